@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"social-credit/internal/config"
+	"social-credit/internal/services"
+)
+
+// runAudit checks the money supply invariant and returns the process exit
+// code: 0 when the ledger balances, 1 otherwise. It only reads the database,
+// so money that was never recorded in the ledger is reported instead of being
+// bootstrapped into it.
+func runAudit(cfg *config.Config) int {
+	ledger := services.NewLedgerService(connectDatabase(cfg, true))
+
+	report, err := ledger.Audit()
+	if err != nil {
+		log.Printf("Failed to run audit: %v", err)
+		return 1
+	}
+
+	fmt.Println(report.String())
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	// Embed the time zone database for /timezone on hosts without one
+	_ "time/tzdata"
 
 	"social-credit/internal/config"
 	"social-credit/internal/handlers"
@@ -22,6 +25,20 @@ func main() {
 		log.Panic("failed to load config: ", err)
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "audit":
+			os.Exit(runAudit(cfg))
+		case "simulate":
+			os.Exit(runSimulate(cfg, os.Args[2:]))
+		default:
+			log.Panic("unknown command: ", os.Args[1])
+		}
+	}
+
+	db := openDatabase(cfg)
+	ledgerService := openLedger(db)
+
 	healthHandler := handlers.NewHealthHandler()
 	go func() {
 		log.Printf("Starting health check server on port 8080")
@@ -38,9 +55,81 @@ func main() {
 	bot.Debug = false
 	log.Printf("Authorized on account %s", bot.Self.UserName)
 
+	policy := services.NewLivePolicy(cfg.App.Economy)
+	creditService := services.NewCreditService(db, ledgerService, policy)
+	economyService := services.NewEconomyService(db)
+	earningService := services.NewEarningService(db, ledgerService, policy)
+	leaderboardService := services.NewLeaderboardService(db)
+	profileService := services.NewProfileService(db, leaderboardService, policy)
+	historyService := services.NewHistoryService(db, ledgerService)
+	seasonService := services.NewSeasonService(bot, cfg, db, creditService)
+	pinnedService := services.NewPinnedService(cfg, db)
+	creditService.OnChange(pinnedService.Touch)
+	membershipService := services.NewMembershipService(db)
+	if err := membershipService.Bootstrap(); err != nil {
+		log.Printf("Failed to bootstrap chat memberships: %v", err)
+	}
+	activityService := services.NewActivityService(bot, cfg, db, creditService)
+
+	if err := seasonService.Start(); err != nil {
+		log.Printf("Failed to start season service: %v", err)
+	}
+
+	if err := activityService.Start(); err != nil {
+		log.Printf("Failed to start activity service: %v", err)
+	}
+
+	messageHandler := handlers.NewMessageHandler(bot, cfg, creditService, ledgerService, economyService, earningService, leaderboardService, profileService, historyService, seasonService, pinnedService, membershipService, activityService)
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	// chat_member updates are only sent when asked for explicitly
+	u.AllowedUpdates = []string{"message", "callback_query", "inline_query", "chat_member"}
+	updates := bot.GetUpdatesChan(u)
+
+	for update := range updates {
+		messageHandler.HandleMessage(update)
+	}
+}
+
+// openDatabase connects to the configured database and migrates its schema.
+func openDatabase(cfg *config.Config) *gorm.DB {
+	db := connectDatabase(cfg, false)
+
+	// Auto-migrate all models
+	if err := db.AutoMigrate(
+		&models.Credit{},
+		&models.ActivityStatus{},
+		&models.ActivityCheck{},
+		&models.ActivityTransition{},
+		&models.Account{},
+		&models.LedgerEntry{},
+		&models.CreditEvent{},
+		&models.Earning{},
+		&models.Season{},
+		&models.SeasonStanding{},
+		&models.Badge{},
+		&models.PinnedBoard{},
+		&models.Membership{},
+	); err != nil {
+		log.Panic("failed to auto-migrate database: ", err)
+	}
+
+	return db
+}
+
+// connectDatabase connects to the configured database without migrating it.
+// Read-only connections refuse every write, for commands that only inspect
+// the data.
+func connectDatabase(cfg *config.Config, readOnly bool) *gorm.DB {
 	var db *gorm.DB
+	var err error
 	if cfg.App.Test || cfg.App.Database.Type == "sqlite" {
-		db, err = gorm.Open(sqlite.Open(cfg.App.Database.SQLite.Path), &gorm.Config{})
+		dsn := cfg.App.Database.SQLite.Path
+		if readOnly {
+			dsn = "file:" + dsn + "?mode=ro"
+		}
+		db, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{})
 		if err != nil {
 			log.Panic("failed to connect to SQLite database: ", err)
 		}
@@ -52,6 +141,9 @@ func main() {
 			cfg.App.Database.Postgres.User,
 			cfg.App.Database.Postgres.Password,
 			cfg.App.Database.Postgres.DBName)
+		if readOnly {
+			dsn += " default_transaction_read_only=on"
+		}
 		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 		if err != nil {
 			log.Panic("failed to connect to PostgreSQL database: ", err)
@@ -60,26 +152,13 @@ func main() {
 	} else {
 		log.Panic("unsupported database type: ", cfg.App.Database.Type)
 	}
+	return db
+}
 
-	// Auto-migrate all models
-	if err := db.AutoMigrate(&models.Credit{}, &models.ActivityStatus{}, &models.ActivityCheck{}); err != nil {
-		log.Panic("failed to auto-migrate database: ", err)
-	}
-
-	creditService := services.NewCreditService(db)
-	activityService := services.NewActivityService(bot, cfg, db, creditService)
-
-	// if err := activityService.Start(); err != nil {
-	// 	log.Printf("Failed to start activity service: %v", err)
-	// }
-
-	messageHandler := handlers.NewMessageHandler(bot, cfg, creditService, activityService)
-
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := bot.GetUpdatesChan(u)
-
-	for update := range updates {
-		messageHandler.HandleMessage(update)
+func openLedger(db *gorm.DB) *services.LedgerService {
+	ledgerService := services.NewLedgerService(db)
+	if err := ledgerService.Bootstrap(); err != nil {
+		log.Panic("failed to bootstrap ledger: ", err)
 	}
+	return ledgerService
 }
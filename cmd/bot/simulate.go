@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"social-credit/internal/config"
+	"social-credit/internal/services"
+)
+
+// runSimulate replays the recorded history under the economy and season
+// sections of an alternative config file and prints the outcome next to the
+// real standings. The database is opened read-only.
+func runSimulate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	policyPath := flags.String("policy", "", "config file whose economy and season sections are simulated")
+	snapshot := flags.String("db", "", "SQLite snapshot to replay instead of the configured database")
+	top := flags.Int("top", 10, "number of leaderboard rows to print")
+	flags.Parse(args)
+
+	if *policyPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: bot simulate -policy alternative.yaml [-db snapshot.db] [-top 10]")
+		return 2
+	}
+
+	alternative, err := config.LoadConfig(*policyPath)
+	if err != nil {
+		log.Printf("Failed to load policy config: %v", err)
+		return 1
+	}
+
+	dbConfig := *cfg
+	if *snapshot != "" {
+		dbConfig.App.Database.Type = "sqlite"
+		dbConfig.App.Database.SQLite.Path = *snapshot
+	}
+	simulator := services.NewSimulator(connectDatabase(&dbConfig, true))
+	actual, err := simulator.Actual()
+	if err != nil {
+		log.Printf("Failed to load standings: %v", err)
+		return 1
+	}
+	simulated, err := simulator.Run(services.NewPolicy(alternative.App.Economy), alternative.App.Seasons.ResetPercent)
+	if err != nil {
+		log.Printf("Failed to run simulation: %v", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tACTUAL\tSIMULATED")
+	printMetrics(w, "credit", actual.Credits(), simulated.Credits())
+	printMetrics(w, "money", actual.Money(), simulated.Money())
+	w.Flush()
+
+	credit := func(s services.Standing) int { return s.Credit }
+	money := func(s services.Standing) int { return s.Money }
+
+	fmt.Println("\nSocialCredit leaderboard")
+	printLeaderboards(actual.Top(*top, credit), simulated.Top(*top, credit), credit)
+	fmt.Println("\nMoney leaderboard")
+	printLeaderboards(actual.Top(*top, money), simulated.Top(*top, money), money)
+	return 0
+}
+
+func printMetrics(w *tabwriter.Writer, name string, actual, simulated []int) {
+	fmt.Fprintf(w, "%s total\t%d\t%d\n", name, sum(actual), sum(simulated))
+	fmt.Fprintf(w, "%s mean\t%.1f\t%.1f\n", name, services.Mean(actual), services.Mean(simulated))
+	fmt.Fprintf(w, "%s median\t%.1f\t%.1f\n", name, services.Median(actual), services.Median(simulated))
+	fmt.Fprintf(w, "%s gini\t%.2f\t%.2f\n", name, services.Gini(actual), services.Gini(simulated))
+	fmt.Fprintf(w, "%s top 1%%\t%.1f%%\t%.1f%%\n", name, services.TopShare(actual, 0.01)*100, services.TopShare(simulated, 0.01)*100)
+}
+
+func printLeaderboards(actual, simulated services.Standings, value func(services.Standing) int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tACTUAL\t\tSIMULATED\t")
+	for i := 0; i < max(len(actual), len(simulated)); i++ {
+		fmt.Fprintf(w, "%d\t", i+1)
+		for _, board := range []services.Standings{actual, simulated} {
+			if i < len(board) {
+				fmt.Fprintf(w, "@%s\t%d\t", board[i].Username, value(board[i]))
+			} else {
+				fmt.Fprint(w, "\t\t")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,236 @@
+// Package charts renders leaderboards and histories as PNG images without any
+// external service.
+package charts
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	width   = 800
+	margin  = 24
+	rowSize = 30
+	barGap  = 8
+)
+
+var (
+	background = color.RGBA{0x1e, 0x1f, 0x26, 0xff}
+	foreground = color.RGBA{0xe8, 0xe8, 0xee, 0xff}
+	muted      = color.RGBA{0x6c, 0x6f, 0x80, 0xff}
+	positive   = color.RGBA{0x4c, 0xc3, 0x8a, 0xff}
+	negative   = color.RGBA{0xe0, 0x5d, 0x5d, 0xff}
+	line       = color.RGBA{0x5d, 0xa9, 0xe9, 0xff}
+
+	textFace  = mustFace(goregular.TTF, 15)
+	titleFace = mustFace(gobold.TTF, 20)
+)
+
+func mustFace(ttf []byte, size float64) font.Face {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic(err)
+	}
+	return face
+}
+
+type Bar struct {
+	Label string
+	Value int
+}
+
+// BarChart draws one horizontal bar per entry, top to bottom. Negative values
+// grow to the left of the zero line.
+func BarChart(title string, bars []Bar) ([]byte, error) {
+	height := margin*3 + titleHeight() + max(len(bars), 1)*rowSize
+	img := newCanvas(width, height)
+	drawText(img, titleFace, title, margin, margin+titleHeight(), foreground)
+
+	labelWidth := 0
+	lo, hi := 0, 0
+	for _, b := range bars {
+		labelWidth = max(labelWidth, measure(textFace, b.Label))
+		lo, hi = min(lo, b.Value), max(hi, b.Value)
+	}
+	labelWidth = min(labelWidth, width/3)
+	valueWidth := measure(textFace, fmt.Sprint(-max(hi, -lo))) + barGap
+
+	left := margin + labelWidth + barGap
+	span := width - margin - valueWidth - left
+	zero := left
+	if hi > lo {
+		zero = left + span*(-lo)/(hi-lo)
+	}
+
+	top := margin*2 + titleHeight()
+	for i, b := range bars {
+		y := top + i*rowSize
+		baseline := y + rowSize/2 + 5
+		drawText(img, textFace, truncate(textFace, b.Label, labelWidth), margin, baseline, foreground)
+
+		length := 0
+		if hi > lo {
+			length = span * b.Value / (hi - lo)
+		}
+		x0, x1 := zero, zero+length
+		fill := positive
+		if b.Value < 0 {
+			x0, x1 = x1, x0
+			fill = negative
+		}
+		fillRect(img, x0, y+4, max(x1, x0+2), y+rowSize-4, fill)
+
+		valueX := x1 + barGap
+		if b.Value < 0 {
+			valueX = zero + barGap
+		}
+		drawText(img, textFace, fmt.Sprint(b.Value), valueX, baseline, muted)
+	}
+	fillRect(img, zero, top, zero+1, top+len(bars)*rowSize, muted)
+
+	return encode(img)
+}
+
+type Point struct {
+	Time  time.Time
+	Value int
+}
+
+// LineChart draws points, which must be sorted by time, as a line over time.
+func LineChart(title string, points []Point) ([]byte, error) {
+	const height = 420
+	img := newCanvas(width, height)
+	drawText(img, titleFace, title, margin, margin+titleHeight(), foreground)
+	if len(points) == 0 {
+		drawText(img, textFace, "No history yet", margin, height/2, muted)
+		return encode(img)
+	}
+
+	lo, hi := points[0].Value, points[0].Value
+	for _, p := range points {
+		lo, hi = min(lo, p.Value), max(hi, p.Value)
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	start, end := points[0].Time, points[len(points)-1].Time
+	if !end.After(start) {
+		end = start.Add(time.Hour)
+	}
+
+	axisWidth := max(measure(textFace, fmt.Sprint(lo)), measure(textFace, fmt.Sprint(hi))) + barGap
+	left, right := margin+axisWidth, width-margin
+	top, bottom := margin*2+titleHeight(), height-margin*2
+
+	x := func(t time.Time) int {
+		return left + int(float64(right-left)*float64(t.Sub(start))/float64(end.Sub(start)))
+	}
+	y := func(v int) int {
+		return bottom - (bottom-top)*(v-lo)/(hi-lo)
+	}
+
+	for _, v := range []int{lo, (lo + hi) / 2, hi} {
+		fillRect(img, left, y(v), right, y(v)+1, muted)
+		drawText(img, textFace, fmt.Sprint(v), margin, y(v)+5, muted)
+	}
+	if lo < 0 && hi > 0 {
+		fillRect(img, left, y(0), right, y(0)+1, foreground)
+	}
+	drawText(img, textFace, start.Format("2006-01-02"), left, height-margin, muted)
+	endLabel := end.Format("2006-01-02")
+	drawText(img, textFace, endLabel, right-measure(textFace, endLabel), height-margin, muted)
+
+	for i := 1; i < len(points); i++ {
+		// Values hold until the next change, so draw steps
+		px, py := x(points[i-1].Time), y(points[i-1].Value)
+		cx, cy := x(points[i].Time), y(points[i].Value)
+		drawLine(img, px, py, cx, py, line)
+		drawLine(img, cx, py, cx, cy, line)
+	}
+	last := points[len(points)-1]
+	fillRect(img, x(last.Time)-3, y(last.Value)-3, x(last.Time)+3, y(last.Value)+3, line)
+
+	return encode(img)
+}
+
+func newCanvas(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+	return img
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func titleHeight() int {
+	return titleFace.Metrics().Ascent.Ceil()
+}
+
+func drawText(img draw.Image, face font.Face, text string, x, y int, c color.Color) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+// Drawable reports whether the chart fonts have a glyph for every rune of
+// text. They have none for Arabic script, so Persian names are not drawable.
+func Drawable(text string) bool {
+	for _, r := range text {
+		if _, ok := textFace.GlyphAdvance(r); !ok {
+			return false
+		}
+		if _, ok := titleFace.GlyphAdvance(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func measure(face font.Face, text string) int {
+	return font.MeasureString(face, text).Ceil()
+}
+
+// truncate shortens text with an ellipsis until it fits in maxWidth pixels.
+func truncate(face font.Face, text string, maxWidth int) string {
+	if measure(face, text) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && measure(face, string(runes)+"…") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func fillRect(img draw.Image, x0, y0, x1, y1 int, c color.Color) {
+	draw.Draw(img, image.Rect(x0, y0, x1, y1), &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// drawLine draws an axis-aligned line two pixels thick.
+func drawLine(img draw.Image, x0, y0, x1, y1 int, c color.Color) {
+	fillRect(img, min(x0, x1)-1, min(y0, y1)-1, max(x0, x1)+1, max(y0, y1)+1, c)
+}
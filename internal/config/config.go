@@ -14,9 +14,13 @@ type Config struct {
 type AppConfig struct {
 	Token         string              `yaml:"token"`
 	Test          bool                `yaml:"test"`
+	Admins        []int64             `yaml:"admins"`
 	Database      DatabaseConfig      `yaml:"database"`
 	Stickers      StickersConfig      `yaml:"stickers"`
 	Capitalist    CapitalistConfig    `yaml:"capitalist"`
+	Economy       EconomyConfig       `yaml:"economy"`
+	Seasons       SeasonsConfig       `yaml:"seasons"`
+	Pinned        PinnedConfig        `yaml:"pinned"`
 	ActivityCheck ActivityCheckConfig `yaml:"activity_check"`
 }
 
@@ -58,13 +62,76 @@ type CapitalistConfig struct {
 	InitialBalance int `yaml:"initial_balance"`
 }
 
+type EconomyConfig struct {
+	Votes    VotesConfig    `yaml:"votes"`
+	Transfer TransferConfig `yaml:"transfer"`
+	Decay    DecayConfig    `yaml:"decay"`
+	Daily    DailyConfig    `yaml:"daily"`
+	Work     WorkConfig     `yaml:"work"`
+	Tiers    []TierConfig   `yaml:"tiers"`
+}
+
+type VotesConfig struct {
+	Positive     int `yaml:"positive"`
+	Negative     int `yaml:"negative"`
+	FraudPenalty int `yaml:"fraud_penalty"`
+	FraudFine    int `yaml:"fraud_fine"`
+}
+
+type TransferConfig struct {
+	Amount     int `yaml:"amount"`
+	TaxPercent int `yaml:"tax_percent"`
+}
+
+type DecayConfig struct {
+	PercentPerDay int `yaml:"percent_per_day"`
+}
+
+type DailyConfig struct {
+	Amount             int `yaml:"amount"`
+	StreakBonusPercent int `yaml:"streak_bonus_percent"`
+	MaxStreak          int `yaml:"max_streak"`
+}
+
+type WorkConfig struct {
+	Amount   int `yaml:"amount"`
+	Cooldown int `yaml:"cooldown"`
+}
+
+type TierConfig struct {
+	Name       string  `yaml:"name"`
+	MinCredit  int     `yaml:"min_credit"`
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+type SeasonsConfig struct {
+	EndsAt       string   `yaml:"ends_at"`
+	LengthDays   int      `yaml:"length_days"`
+	ResetPercent int      `yaml:"reset_percent"`
+	Badges       []string `yaml:"badges"`
+	AnnounceChat string   `yaml:"announce_chat"`
+}
+
+type PinnedConfig struct {
+	Debounce int `yaml:"debounce"`
+}
+
 type ActivityCheckConfig struct {
-	Schedule        string         `yaml:"schedule"`
-	ResponseTimeout int            `yaml:"response_timeout"`
-	MaxRetries      int            `yaml:"max_retries"`
-	RetryInterval   int            `yaml:"retry_interval"`
-	Channels        ChannelsConfig `yaml:"channels"`
-	Rewards         RewardsConfig  `yaml:"rewards"`
+	Schedule        string          `yaml:"schedule"`
+	ResponseTimeout int             `yaml:"response_timeout"`
+	MaxRetries      int             `yaml:"max_retries"`
+	RetryInterval   int             `yaml:"retry_interval"`
+	PollInterval    int             `yaml:"poll_interval"`
+	MaxSnoozeDays   int             `yaml:"max_snooze_days"`
+	DefaultTimeZone string          `yaml:"default_timezone"`
+	Challenges      []string        `yaml:"challenges"`
+	MaxAttempts     int             `yaml:"max_attempts"`
+	PassiveWindow   int             `yaml:"passive_window"`
+	PassiveScore    int             `yaml:"passive_score"`
+	GroupChat       string          `yaml:"group_chat"`
+	Penalties       PenaltiesConfig `yaml:"penalties"`
+	Channels        ChannelsConfig  `yaml:"channels"`
+	Rewards         RewardsConfig   `yaml:"rewards"`
 }
 
 type ChannelsConfig struct {
@@ -72,8 +139,51 @@ type ChannelsConfig struct {
 	Warnings string `yaml:"warnings"`
 }
 
+type PenaltiesConfig struct {
+	Credit      int    `yaml:"credit"`
+	Money       int    `yaml:"money"`
+	Kick        bool   `yaml:"kick"`
+	Mark        string `yaml:"mark"`
+	GracePeriod int    `yaml:"grace_period"`
+}
+
 type RewardsConfig struct {
-	AliveScore int `yaml:"alive_score"`
+	AliveScore    int                 `yaml:"alive_score"`
+	SpeedBonus    int                 `yaml:"speed_bonus"`
+	StreakBonuses []StreakBonusConfig `yaml:"streak_bonuses"`
+}
+
+type StreakBonusConfig struct {
+	Streak int `yaml:"streak"`
+	Bonus  int `yaml:"bonus"`
+}
+
+// defaultConfig holds the values used when a key is missing from the file,
+// so older config files keep their previous behavior.
+func defaultConfig() Config {
+	return Config{
+		App: AppConfig{
+			Economy: EconomyConfig{
+				Votes:    VotesConfig{Positive: 1, Negative: -1, FraudPenalty: -3},
+				Transfer: TransferConfig{Amount: 1},
+				Daily:    DailyConfig{Amount: 5, StreakBonusPercent: 10, MaxStreak: 7},
+				Work:     WorkConfig{Amount: 2, Cooldown: 14400},
+			},
+			Seasons: SeasonsConfig{
+				ResetPercent: 100,
+				Badges:       []string{"🥇", "🥈", "🥉"},
+			},
+			Pinned: PinnedConfig{Debounce: 30},
+			ActivityCheck: ActivityCheckConfig{
+				PollInterval:  60,
+				MaxSnoozeDays: 30,
+				// Unlike the rest, older files lose the plain button, which
+				// scripts can click
+				Challenges:  []string{"arithmetic", "emoji", "word"},
+				MaxAttempts: 3,
+			},
+		},
+	}
 }
 
 func substituteEnvVars(cfg *Config) error {
@@ -102,7 +212,7 @@ func LoadConfig(path string) (*Config, error) {
 	}
 	defer f.Close()
 
-	var cfg Config
+	cfg := defaultConfig()
 	decoder := yaml.NewDecoder(f)
 	err = decoder.Decode(&cfg)
 	if err != nil {
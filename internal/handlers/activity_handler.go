@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"social-credit/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// parseSnoozeDuration accepts Go durations such as "12h" plus whole days
+// ("3d") and weeks ("2w").
+func parseSnoozeDuration(text string) (time.Duration, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(text, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(text)
+}
+
+// handleSnoozeCommand pauses activity checks for the sender for the given
+// duration.
+func (h *MessageHandler) handleSnoozeCommand(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	maxSnooze := time.Duration(h.config.App.ActivityCheck.MaxSnoozeDays) * 24 * time.Hour
+	duration, err := parseSnoozeDuration(update.Message.CommandArguments())
+	if err != nil || duration <= 0 {
+		msg := tgbotapi.NewMessage(chatID, "استفاده: /snooze <مدت>، مثلاً /snooze 3d یا /snooze 12h")
+		h.bot.Send(msg)
+		return
+	}
+	if duration > maxSnooze {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ حداکثر %d روز می‌تونی چک‌ها رو متوقف کنی.", h.config.App.ActivityCheck.MaxSnoozeDays))
+		h.bot.Send(msg)
+		return
+	}
+
+	until := time.Now().Add(duration)
+	ok, err := h.activityService.Snooze(update.Message.From.ID, update.Message.From.UserName, until)
+	if err != nil {
+		log.Printf("Error snoozing activity checks: %v", err)
+		return
+	}
+	text := fmt.Sprintf("😴 تا %s ازت نمی‌پرسیم زنده‌ای یا نه.", until.Format("2006-01-02 15:04"))
+	if !ok {
+		text = "❌ از چک‌های فعالیت خارج شدی. اول با /optin برگرد."
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	h.bot.Send(msg)
+}
+
+// handleOptOutCommand stops activity checks for the sender.
+func (h *MessageHandler) handleOptOutCommand(update tgbotapi.Update) {
+	ok, err := h.activityService.OptOut(update.Message.From.ID, update.Message.From.UserName)
+	if err != nil {
+		log.Printf("Error opting out of activity checks: %v", err)
+		return
+	}
+	text := "🚫 دیگه ازت نمی‌پرسیم زنده‌ای یا نه. هر وقت خواستی با /optin برگرد."
+	if !ok {
+		text = "قبلاً از چک‌های فعالیت خارج شدی."
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	h.bot.Send(msg)
+}
+
+// handleOptInCommand resumes activity checks for the sender after /optout or
+// /snooze.
+func (h *MessageHandler) handleOptInCommand(update tgbotapi.Update) {
+	ok, err := h.activityService.OptIn(update.Message.From.ID, update.Message.From.UserName)
+	if err != nil {
+		log.Printf("Error opting in to activity checks: %v", err)
+		return
+	}
+	text := "✅ چک‌های فعالیت دوباره برات فعال شد."
+	if !ok {
+		text = "چک‌های فعالیتت متوقف نشده بود."
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	h.bot.Send(msg)
+}
+
+// handleSnoozedCommand lists everybody who snoozed or opted out of activity
+// checks.
+func (h *MessageHandler) handleSnoozedCommand(update tgbotapi.Update) {
+	if !h.isAdmin(update.Message.From.ID) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "⛔ This command is for admins only.")
+		h.bot.Send(msg)
+		return
+	}
+
+	statuses, err := h.activityService.Paused()
+	if err != nil {
+		log.Printf("Error getting paused activity checks: %v", err)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("😴 کاربرانی که چک فعالیت ندارن:\n")
+	if len(statuses) == 0 {
+		b.WriteString("هیچ‌کس!")
+	}
+	for _, status := range statuses {
+		if status.State == models.ActivitySnoozed {
+			fmt.Fprintf(&b, "@%s — تا %s\n", status.Username, status.SnoozedUntil.Format("2006-01-02 15:04"))
+		} else {
+			fmt.Fprintf(&b, "@%s — خارج شده\n", status.Username)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, b.String())
+	h.bot.Send(msg)
+}
+
+// handleTimeZoneCommand shows or sets the time zone the sender's quiet hours
+// are read in.
+func (h *MessageHandler) handleTimeZoneCommand(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	name := strings.TrimSpace(update.Message.CommandArguments())
+	if name == "" {
+		status, err := h.activityService.Status(update.Message.From.ID, update.Message.From.UserName)
+		if err != nil {
+			log.Printf("Error getting activity status: %v", err)
+			return
+		}
+		current := status.TimeZone
+		if current == "" {
+			current = h.config.App.ActivityCheck.DefaultTimeZone
+		}
+		if current == "" {
+			current = "UTC"
+		}
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🕰️ منطقه زمانی تو: %s\nبرای تغییر: /timezone Asia/Tehran", current))
+		h.bot.Send(msg)
+		return
+	}
+
+	if err := h.activityService.SetTimeZone(update.Message.From.ID, update.Message.From.UserName, name); err != nil {
+		msg := tgbotapi.NewMessage(chatID, "❌ این منطقه زمانی رو نمی‌شناسم. مثلاً: /timezone Asia/Tehran")
+		h.bot.Send(msg)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🕰️ منطقه زمانی تو شد %s", name))
+	h.bot.Send(msg)
+}
+
+// parseClock parses "HH:MM" or "HH" into minutes after midnight.
+func parseClock(text string) (int, error) {
+	layout := "15:04"
+	if !strings.Contains(text, ":") {
+		layout = "15"
+	}
+	t, err := time.Parse(layout, text)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// handleQuietHoursCommand shows or sets the local hours during which the
+// sender is never pinged, for example "/quiethours 23:00-08:00" or
+// "/quiethours off".
+func (h *MessageHandler) handleQuietHoursCommand(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	userID, username := update.Message.From.ID, update.Message.From.UserName
+	arg := strings.ToLower(strings.TrimSpace(update.Message.CommandArguments()))
+	usage := "استفاده: /quiethours 23:00-08:00 یا /quiethours off"
+
+	switch arg {
+	case "":
+		status, err := h.activityService.Status(userID, username)
+		if err != nil {
+			log.Printf("Error getting activity status: %v", err)
+			return
+		}
+		text := "🔔 ساعت سکوت نداری.\n" + usage
+		if status.QuietStart != status.QuietEnd {
+			text = fmt.Sprintf("🔕 ساعت سکوت: %s تا %s\n%s", formatClock(status.QuietStart), formatClock(status.QuietEnd), usage)
+		}
+		msg := tgbotapi.NewMessage(chatID, text)
+		h.bot.Send(msg)
+		return
+	case "off":
+		if err := h.activityService.SetQuietHours(userID, username, 0, 0); err != nil {
+			log.Printf("Error clearing quiet hours: %v", err)
+			return
+		}
+		msg := tgbotapi.NewMessage(chatID, "🔔 ساعت سکوت خاموش شد.")
+		h.bot.Send(msg)
+		return
+	}
+
+	from, to, ok := strings.Cut(arg, "-")
+	start, err1 := parseClock(strings.TrimSpace(from))
+	end, err2 := parseClock(strings.TrimSpace(to))
+	if !ok || err1 != nil || err2 != nil || start == end {
+		msg := tgbotapi.NewMessage(chatID, usage)
+		h.bot.Send(msg)
+		return
+	}
+
+	if err := h.activityService.SetQuietHours(userID, username, start, end); err != nil {
+		log.Printf("Error setting quiet hours: %v", err)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🔕 از %s تا %s بهت پیام نمی‌دیم.", formatClock(start), formatClock(end)))
+	h.bot.Send(msg)
+}
+
+// handleAliveStatsCommand shows how quickly the replied-to user, the given
+// @username, or the sender answers activity checks.
+func (h *MessageHandler) handleAliveStatsCommand(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	userID := update.Message.From.ID
+	username := update.Message.From.UserName
+	args := strings.Fields(update.Message.CommandArguments())[1:]
+	user, _, err := h.resolveTarget(update, args)
+	if err == nil {
+		userID = int64(user.UserID)
+		username = user.Username
+	} else if !errors.Is(err, errNoTarget) {
+		msg := tgbotapi.NewMessage(chatID, "❌ همچین کاربری رو نمی‌شناسم.")
+		h.bot.Send(msg)
+		return
+	}
+
+	stats, err := h.activityService.ResponseStats(userID)
+	if err != nil {
+		log.Printf("Error getting response stats: %v", err)
+		return
+	}
+
+	text := fmt.Sprintf("⏱ @%s هنوز به هیچ چکی جواب نداده.", username)
+	if stats.Count > 0 {
+		text = fmt.Sprintf("⏱ زمان جواب دادن @%s به %d چک:\nمیانه: %s\np90: %s",
+			username, stats.Count, stats.Median, stats.P90)
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	h.bot.Send(msg)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"social-credit/internal/charts"
+	"social-credit/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const historyChartDays = 30
+
+// sendLeaderboardChart sends the requested leaderboard page as a bar chart.
+func (h *MessageHandler) sendLeaderboardChart(update tgbotapi.Update, req leaderboardRequest) {
+	lb := leaderboards[req.name]
+	page, err := h.leaderboard.Page(lb.query(req))
+	if err != nil {
+		log.Printf("Error getting %s leaderboard: %v", req.name, err)
+		return
+	}
+
+	bars := make([]charts.Bar, 0, len(page.Entries))
+	for _, entry := range page.Entries {
+		bars = append(bars, charts.Bar{Label: fmt.Sprintf("%d. %s", entry.Rank, chartName(entry.UserID, entry.Username)), Value: entry.Value})
+	}
+
+	title := lb.chartTitle
+	if window, ok := windowTitles[req.window]; ok {
+		title += " — " + window
+	}
+	img, err := charts.BarChart(title, bars)
+	if err != nil {
+		log.Printf("Error rendering leaderboard chart: %v", err)
+		return
+	}
+	h.sendChart(update.Message.Chat.ID, img)
+}
+
+// sendHistoryChart sends the SocialCredit of user over the last 30 days as a
+// line chart.
+func (h *MessageHandler) sendHistoryChart(update tgbotapi.Update, user *models.Credit) {
+	since := time.Now().AddDate(0, 0, -historyChartDays)
+	series, err := h.history.CreditSeries(int64(user.UserID), since)
+	if err != nil {
+		log.Printf("Error getting credit history: %v", err)
+		return
+	}
+
+	points := make([]charts.Point, 0, len(series))
+	for _, p := range series {
+		points = append(points, charts.Point{Time: p.Time, Value: p.Value})
+	}
+	img, err := charts.LineChart(fmt.Sprintf("%s — SocialCredit, last %d days", chartName(int64(user.UserID), user.Username), historyChartDays), points)
+	if err != nil {
+		log.Printf("Error rendering history chart: %v", err)
+		return
+	}
+	h.sendChart(update.Message.Chat.ID, img)
+}
+
+// chartName returns how a chart names a user: @username, or the user ID when
+// the chart fonts cannot draw the username.
+func chartName(userID int64, username string) string {
+	if username == "" || !charts.Drawable(username) {
+		return fmt.Sprintf("user %d", userID)
+	}
+	return "@" + username
+}
+
+func (h *MessageHandler) sendChart(chatID int64, img []byte) {
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: img})
+	if _, err := h.bot.Send(photo); err != nil {
+		log.Printf("Error sending chart: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import "testing"
+
+func TestChartName(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     string
+	}{
+		{name: "latin username", username: "alice_42", want: "@alice_42"},
+		{name: "persian username", username: "علی", want: "user 7"},
+		{name: "mixed username", username: "ali_علی", want: "user 7"},
+		{name: "no username", username: "", want: "user 7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chartName(7, tt.username); got != tt.want {
+				t.Errorf("chartName(7, %q) = %q, want %q", tt.username, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"social-credit/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (h *MessageHandler) handleDailyCommand(update tgbotapi.Update) {
+	receipt, err := h.earning.ClaimDaily(update.Message.From.ID)
+	if h.replyCooldown(update, err, "/daily") {
+		return
+	}
+	if err != nil {
+		log.Printf("Error claiming daily reward: %v", err)
+		return
+	}
+	h.pinned.Touch(update.Message.Chat.ID)
+
+	user, _ := h.credit.GetUserCredit(int(update.Message.From.ID))
+	msgText := fmt.Sprintf("🎁 @%s claimed %d money!\n🔥 Streak: %d day(s)\nBalance: %d",
+		user.Username,
+		receipt.Amount,
+		receipt.Streak,
+		user.Money)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+	h.bot.Send(msg)
+}
+
+func (h *MessageHandler) handleWorkCommand(update tgbotapi.Update) {
+	receipt, err := h.earning.Work(update.Message.From.ID)
+	if h.replyCooldown(update, err, "/work") {
+		return
+	}
+	if err != nil {
+		log.Printf("Error paying work reward: %v", err)
+		return
+	}
+	h.pinned.Touch(update.Message.Chat.ID)
+
+	user, _ := h.credit.GetUserCredit(int(update.Message.From.ID))
+	msgText := fmt.Sprintf("⚒️ @%s worked and earned %d money!\n", user.Username, receipt.Amount)
+	if receipt.Tier != "" {
+		msgText += fmt.Sprintf("Tier: %s\n", receipt.Tier)
+	}
+	msgText += fmt.Sprintf("Balance: %d", user.Money)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+	h.bot.Send(msg)
+}
+
+// replyCooldown tells the user how long to wait if err is a cooldown and
+// reports whether it was one.
+func (h *MessageHandler) replyCooldown(update tgbotapi.Update, err error, command string) bool {
+	var cooldown *services.CooldownError
+	if !errors.As(err, &cooldown) {
+		return false
+	}
+	msgText := fmt.Sprintf("⏳ You can use %s again in %s.", command, cooldown.Remaining.Round(time.Minute))
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+	h.bot.Send(msg)
+	return true
+}
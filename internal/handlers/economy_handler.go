@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const histogramWidth = 12
+
+func (h *MessageHandler) handleEconomyCommand(update tgbotapi.Update) {
+	stats, err := h.economy.Stats()
+	if err != nil {
+		log.Printf("Error getting economy stats: %v", err)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("📊 State of the Economy:\n")
+	fmt.Fprintf(&b, "Citizens: %d\n", stats.Citizens)
+	fmt.Fprintf(&b, "Money supply: %d\n", stats.MoneySupply)
+	fmt.Fprintf(&b, "Mean balance: %.1f\n", stats.MeanBalance)
+	fmt.Fprintf(&b, "Median balance: %.1f\n", stats.MedianBalance)
+	fmt.Fprintf(&b, "Gini coefficient: %.2f\n", stats.Gini)
+	fmt.Fprintf(&b, "Top 1%% share: %.1f%%\n", stats.TopOnePercent*100)
+	fmt.Fprintf(&b, "Velocity: %.2f transfers/day\n", stats.Velocity)
+
+	if len(stats.Credits) > 0 {
+		b.WriteString("\n🌟 SocialCredit distribution:\n")
+		largest := 0
+		for _, bucket := range stats.Credits {
+			largest = max(largest, bucket.Count)
+		}
+		for _, bucket := range stats.Credits {
+			bar := strings.Repeat("█", bucket.Count*histogramWidth/largest)
+			fmt.Fprintf(&b, "%d..%d | %s %d\n", bucket.Min, bucket.Max, bar, bucket.Count)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, b.String())
+	h.bot.Send(msg)
+}
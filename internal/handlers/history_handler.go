@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"social-credit/internal/models"
+	"social-credit/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	historyLimit         = 15
+	historySparklineDays = 14
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+var metricIcons = map[string]string{
+	services.MetricCredit: "🌟",
+	services.MetricMoney:  "💰",
+	services.MetricAlive:  "🟢",
+}
+
+// handleHistoryCommand lists the recent changes to the credit, money and alive
+// score of the replied-to user, the given @username, or the sender, under a
+// sparkline of their daily SocialCredit. With "chart" it sends the last 30
+// days of SocialCredit as an image instead.
+func (h *MessageHandler) handleHistoryCommand(update tgbotapi.Update) {
+	user, args, err := h.resolveTarget(update, strings.Fields(update.Message.CommandArguments()))
+	if errors.Is(err, errNoTarget) {
+		user, err = h.credit.GetUserCredit(int(update.Message.From.ID))
+	}
+	if err != nil {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ I don't know that citizen.")
+		h.bot.Send(msg)
+		return
+	}
+
+	if slices.Contains(args, "chart") {
+		h.sendHistoryChart(update, user)
+		return
+	}
+
+	daily, err := h.history.DailyCredit(int64(user.UserID), historySparklineDays)
+	if err != nil {
+		log.Printf("Error getting credit history: %v", err)
+		return
+	}
+	timeline, err := h.history.Timeline(int64(user.UserID), historyLimit)
+	if err != nil {
+		log.Printf("Error getting history: %v", err)
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📜 History of @%s\n", user.Username)
+	fmt.Fprintf(&b, "🌟 Last %d days: %s (%d → %d)\n\n", historySparklineDays, sparkline(daily), daily[0], daily[len(daily)-1])
+	if len(timeline) == 0 {
+		b.WriteString("Nothing has happened yet.\n")
+	}
+	for _, entry := range timeline {
+		fmt.Fprintf(&b, "%s %s %+d %s", entry.Time.Format("01-02 15:04"), metricIcons[entry.Metric], entry.Amount, entry.Reason)
+		if entry.Actor != "" && entry.Actor != user.Username {
+			fmt.Fprintf(&b, " — %s", formatActor(entry.Actor))
+		}
+		if entry.Memo != "" {
+			fmt.Fprintf(&b, " (%s)", entry.Memo)
+		}
+		b.WriteString("\n")
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, b.String())
+	h.bot.Send(msg)
+}
+
+// formatActor prefixes usernames with @ and leaves account kinds such as
+// "treasury" or "mint" as they are.
+func formatActor(actor string) string {
+	switch actor {
+	case models.AccountMint, models.AccountTreasury:
+		return actor
+	}
+	return "@" + actor
+}
+
+// sparkline draws values as a row of block characters scaled between their
+// minimum and maximum.
+func sparkline(values []int) string {
+	lo, hi := slices.Min(values), slices.Max(values)
+	spark := make([]rune, 0, len(values))
+	for _, v := range values {
+		level := len(sparkBlocks) / 2
+		if hi > lo {
+			level = (v - lo) * (len(sparkBlocks) - 1) / (hi - lo)
+		}
+		spark = append(spark, sparkBlocks[level])
+	}
+	return string(spark)
+}
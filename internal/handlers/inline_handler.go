@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"social-credit/internal/models"
+	"social-credit/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	inlineResultLimit = 10
+	inlineCacheTime   = 30
+)
+
+// handleInlineQuery answers "@bot username" with SocialCredit cards of the
+// matching citizens, or the sender's own card for an empty query, so they can
+// be shared into any chat.
+func (h *MessageHandler) handleInlineQuery(query *tgbotapi.InlineQuery) {
+	username := strings.TrimPrefix(strings.TrimSpace(query.Query), "@")
+
+	var users []models.Credit
+	if username == "" {
+		user, err := h.credit.GetUserCredit(int(query.From.ID))
+		if err == nil {
+			users = append(users, *user)
+		}
+	} else {
+		var err error
+		users, err = h.credit.SearchUsers(username, inlineResultLimit)
+		if err != nil {
+			log.Printf("Error searching users: %v", err)
+			return
+		}
+	}
+
+	results := make([]interface{}, 0, len(users))
+	for _, user := range users {
+		// Inline queries do not say which chat they are typed in, so the
+		// rank is among all citizens
+		rank, err := h.leaderboard.Rank(services.LeaderboardQuery{Board: services.BoardCredit}, int64(user.UserID))
+		if err != nil {
+			log.Printf("Error getting rank: %v", err)
+			return
+		}
+		position := 0
+		if rank != nil {
+			position = rank.Rank
+		}
+
+		card := formatCreditCard(user, position, h.credit.Tier(user.Credit))
+		article := tgbotapi.NewInlineQueryResultArticle(strconv.Itoa(user.UserID), "@"+user.Username, card)
+		article.Description = fmt.Sprintf("🌟 %d SocialCredit%s · 💰 %d", user.Credit, formatRank(position), user.Money)
+		results = append(results, article)
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		IsPersonal:    username == "",
+	}
+	if _, err := h.bot.Request(answer); err != nil {
+		log.Printf("Error answering inline query: %v", err)
+	}
+}
+
+func formatCreditCard(user models.Credit, rank int, tier string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🪪 SocialCredit card of @%s\n", user.Username)
+	if tier != "" {
+		fmt.Fprintf(&b, "🏅 Tier: %s\n", tier)
+	}
+	fmt.Fprintf(&b, "🌟 SocialCredit: %d%s\n", user.Credit, formatRank(rank))
+	fmt.Fprintf(&b, "💰 Money: %d\n", user.Money)
+	fmt.Fprintf(&b, "🟢 Alive score: %d", user.AliveScore)
+	return b.String()
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"social-credit/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	leaderboardCallbackPrefix = "lb:"
+	defaultPageSize           = 10
+	maxPageSize               = 50
+)
+
+type leaderboard struct {
+	board        string
+	title        string
+	chartTitle   string
+	window       string
+	ascending    bool
+	negativeOnly bool
+}
+
+// leaderboards maps the command that shows a leaderboard to its board.
+var leaderboards = map[string]leaderboard{
+	"credits": {board: services.BoardCredit, title: "🌟 SocialCredit Leaderboard", chartTitle: "SocialCredit Leaderboard"},
+	"money":   {board: services.BoardMoney, title: "💰 Money Leaderboard", chartTitle: "Money Leaderboard"},
+	"alive":   {board: services.BoardAlive, title: "🌟 امتیاز زنده بودن", chartTitle: "Alive Score Leaderboard"},
+	"shame": {
+		board:      services.BoardCredit,
+		title:      "🤡 Hall of Shame",
+		chartTitle: "Hall of Shame",
+		ascending:  true,
+	},
+	"fallers": {
+		board:        services.BoardCredit,
+		title:        "📉 Biggest Fallers",
+		chartTitle:   "Biggest Fallers",
+		window:       services.WindowWeek,
+		ascending:    true,
+		negativeOnly: true,
+	},
+	"haters": {
+		board:      services.BoardNegativeVotes,
+		title:      "👎 Most Negative Votes Given",
+		chartTitle: "Most Negative Votes Given",
+	},
+}
+
+func (lb leaderboard) query(req leaderboardRequest) services.LeaderboardQuery {
+	return services.LeaderboardQuery{
+		Board:        lb.board,
+		Window:       req.window,
+		ChatID:       req.chatID,
+		Ascending:    lb.ascending,
+		NegativeOnly: lb.negativeOnly,
+		Offset:       req.page * req.size,
+		Limit:        req.size,
+	}
+}
+
+// leaderboardRequest is everything needed to render one page of a
+// leaderboard. Apart from the chat, which the callback already carries, it
+// round-trips through the callback data of the navigation buttons.
+type leaderboardRequest struct {
+	name   string
+	window string
+	page   int
+	size   int
+	chatID int64
+}
+
+// memberChatID returns the chat whose members a leaderboard requested in chat
+// is limited to: the group itself, or none in private chats.
+func memberChatID(chat *tgbotapi.Chat) int64 {
+	if chat == nil || chat.IsPrivate() {
+		return 0
+	}
+	return chat.ID
+}
+
+var windowTitles = map[string]string{
+	services.WindowDay:   "today",
+	services.WindowWeek:  "this week",
+	services.WindowMonth: "this month",
+}
+
+func (r leaderboardRequest) callbackData(page int) string {
+	return fmt.Sprintf("%s%s:%s:%d:%d", leaderboardCallbackPrefix, r.name, r.window, page, r.size)
+}
+
+// parseLeaderboardCallback reads a request back from callback data, which
+// clients can forge, so anything but a known board, a known window and a
+// page whose offset fits in an int is rejected.
+func parseLeaderboardCallback(data string) (leaderboardRequest, bool) {
+	parts := strings.Split(strings.TrimPrefix(data, leaderboardCallbackPrefix), ":")
+	if len(parts) != 4 {
+		return leaderboardRequest{}, false
+	}
+	page, err1 := strconv.Atoi(parts[2])
+	size, err2 := strconv.Atoi(parts[3])
+	if _, ok := leaderboards[parts[0]]; !ok || !services.IsWindow(parts[1]) || err1 != nil || err2 != nil || page < 0 || page > math.MaxInt/maxPageSize {
+		return leaderboardRequest{}, false
+	}
+	return leaderboardRequest{name: parts[0], window: parts[1], page: page, size: min(max(size, 1), maxPageSize)}, true
+}
+
+// handleLeaderboardCommand shows the first page of the leaderboard named by
+// the command. Optional arguments pick a time window (day, week, month or
+// all) and the page size, and "chart" sends the page as an image instead.
+func (h *MessageHandler) handleLeaderboardCommand(update tgbotapi.Update) {
+	req := leaderboardRequest{
+		name:   update.Message.Command(),
+		window: services.WindowAll,
+		size:   defaultPageSize,
+		chatID: memberChatID(update.Message.Chat),
+	}
+	if window := leaderboards[req.name].window; window != "" {
+		req.window = window
+	}
+	chart := false
+	for _, arg := range strings.Fields(update.Message.CommandArguments()) {
+		arg = strings.ToLower(arg)
+		if size, err := strconv.Atoi(arg); err == nil {
+			req.size = min(max(size, 1), maxPageSize)
+		} else if services.IsWindow(arg) {
+			req.window = arg
+		} else if arg == "chart" {
+			chart = true
+		}
+	}
+
+	if chart {
+		h.sendLeaderboardChart(update, req)
+		return
+	}
+
+	text, markup, err := h.renderLeaderboard(req, update.Message.From.ID)
+	if err != nil {
+		log.Printf("Error getting %s leaderboard: %v", req.name, err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	if markup != nil {
+		msg.ReplyMarkup = markup
+	}
+	h.bot.Send(msg)
+}
+
+// handleLeaderboardCallback edits a leaderboard message in place when one of
+// its navigation buttons is pressed.
+func (h *MessageHandler) handleLeaderboardCallback(query *tgbotapi.CallbackQuery) {
+	defer h.bot.Request(tgbotapi.NewCallback(query.ID, ""))
+
+	req, ok := parseLeaderboardCallback(query.Data)
+	if !ok || query.Message == nil {
+		return
+	}
+	req.chatID = memberChatID(query.Message.Chat)
+
+	text, markup, err := h.renderLeaderboard(req, query.From.ID)
+	if err != nil {
+		log.Printf("Error getting %s leaderboard: %v", req.name, err)
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ReplyMarkup = markup
+	h.bot.Send(edit)
+}
+
+// renderLeaderboard returns the text of one page and its navigation buttons,
+// followed by the position of userID if it is not on the page and, on the
+// alive board, their streak.
+func (h *MessageHandler) renderLeaderboard(req leaderboardRequest, userID int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	lb := leaderboards[req.name]
+	query := lb.query(req)
+
+	page, err := h.leaderboard.Page(query)
+	if err != nil {
+		return "", nil, err
+	}
+	pages := max(int((page.Total+int64(req.size)-1)/int64(req.size)), 1)
+
+	var b strings.Builder
+	b.WriteString(lb.title)
+	if title, ok := windowTitles[req.window]; ok {
+		b.WriteString(" — " + title)
+	}
+	if pages > 1 {
+		fmt.Fprintf(&b, " (%d/%d)", req.page+1, pages)
+	}
+	b.WriteString(":\n")
+
+	entries := page.Entries
+	onPage := false
+	for _, entry := range page.Entries {
+		onPage = onPage || entry.UserID == userID
+	}
+	if !onPage {
+		own, err := h.leaderboard.Rank(query, userID)
+		if err != nil {
+			return "", nil, err
+		}
+		if own != nil {
+			entries = append(entries, *own)
+		}
+	}
+
+	marked, err := h.inactiveMarks(entries)
+	if err != nil {
+		return "", nil, err
+	}
+	for i, entry := range entries {
+		if i == len(page.Entries) {
+			b.WriteString("…\n")
+		}
+		fmt.Fprintf(&b, "%d. @%s%s — %d\n", entry.Rank, entry.Username, marked[entry.UserID], entry.Value)
+	}
+
+	if lb.board == services.BoardAlive && userID != 0 {
+		current, best, err := h.activityService.Streak(userID)
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(&b, "\n🔥 استریک شما: %d (بهترین: %d)\n", current, best)
+	}
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	if req.page > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("◀️", req.callbackData(req.page-1)))
+	}
+	if req.page < pages-1 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("▶️", req.callbackData(req.page+1)))
+	}
+	if len(buttons) == 0 {
+		return b.String(), nil, nil
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(buttons)
+	return b.String(), &markup, nil
+}
+
+// inactiveMarks returns the configured mark for every inactive user among
+// entries.
+func (h *MessageHandler) inactiveMarks(entries []services.LeaderboardEntry) (map[int64]string, error) {
+	mark := h.config.App.ActivityCheck.Penalties.Mark
+	if mark == "" || len(entries) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]int64, len(entries))
+	for i, entry := range entries {
+		userIDs[i] = entry.UserID
+	}
+	inactive, err := h.activityService.InactiveUsers(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	marks := make(map[int64]string, len(inactive))
+	for userID := range inactive {
+		marks[userID] = " " + mark
+	}
+	return marks, nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"testing"
+
+	"social-credit/internal/services"
+)
+
+func TestParseLeaderboardCallback(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want leaderboardRequest
+		ok   bool
+	}{
+		{name: "first page", data: "lb:credits:all:0:10", want: leaderboardRequest{name: "credits", window: services.WindowAll, page: 0, size: 10}, ok: true},
+		{name: "windowed page", data: "lb:money:week:3:5", want: leaderboardRequest{name: "money", window: services.WindowWeek, page: 3, size: 5}, ok: true},
+		{name: "page size capped", data: "lb:alive:all:1:1000", want: leaderboardRequest{name: "alive", window: services.WindowAll, page: 1, size: maxPageSize}, ok: true},
+		{name: "page size raised to one", data: "lb:alive:all:1:-5", want: leaderboardRequest{name: "alive", window: services.WindowAll, page: 1, size: 1}, ok: true},
+		{name: "negative page", data: "lb:credits:all:-1:10"},
+		{name: "page not a number", data: "lb:credits:all:one:10"},
+		{name: "size not a number", data: "lb:credits:all:0:ten"},
+		{name: "page overflows the offset", data: "lb:credits:all:9223372036854775807:10"},
+		{name: "page out of range", data: "lb:credits:all:99999999999999999999:10"},
+		{name: "unknown board", data: "lb:secret:all:0:10"},
+		{name: "unknown window", data: "lb:credits:year:0:10"},
+		{name: "empty window", data: "lb:credits::0:10"},
+		{name: "too few parts", data: "lb:credits:all:0"},
+		{name: "too many parts", data: "lb:credits:all:0:10:1"},
+		{name: "empty", data: "lb:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseLeaderboardCallback(tt.data)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseLeaderboardCallback(%q) = %+v, %v, want %+v, %v", tt.data, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// trackMembership records the sender of a group message, and anybody the
+// message says joined or left, as members of the chat.
+func (h *MessageHandler) trackMembership(message *tgbotapi.Message) {
+	chatID := memberChatID(message.Chat)
+	if chatID == 0 {
+		return
+	}
+
+	if message.From != nil && !message.From.IsBot {
+		if err := h.membership.Spoke(chatID, message.From.ID); err != nil {
+			log.Printf("Error recording chat member: %v", err)
+		}
+	}
+	for _, user := range message.NewChatMembers {
+		if user.IsBot {
+			continue
+		}
+		if err := h.membership.Joined(chatID, user.ID); err != nil {
+			log.Printf("Error recording chat member: %v", err)
+		}
+	}
+	if user := message.LeftChatMember; user != nil {
+		if err := h.membership.Left(chatID, user.ID); err != nil {
+			log.Printf("Error recording chat member: %v", err)
+		}
+	}
+}
+
+// handleChatMemberUpdate records joins, leaves and bans reported by Telegram.
+// The bot only receives these in chats where it is an administrator.
+func (h *MessageHandler) handleChatMemberUpdate(update *tgbotapi.ChatMemberUpdated) {
+	chatID := memberChatID(&update.Chat)
+	user := update.NewChatMember.User
+	if chatID == 0 || user == nil || user.IsBot {
+		return
+	}
+
+	var err error
+	if update.NewChatMember.HasLeft() || update.NewChatMember.WasKicked() {
+		err = h.membership.Left(chatID, user.ID)
+	} else {
+		err = h.membership.Joined(chatID, user.ID)
+	}
+	if err != nil {
+		log.Printf("Error recording chat member: %v", err)
+	}
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 
 	"social-credit/internal/config"
+	"social-credit/internal/models"
 	"social-credit/internal/services"
 
 	"slices"
@@ -16,50 +20,52 @@ type MessageHandler struct {
 	bot             *tgbotapi.BotAPI
 	config          *config.Config
 	credit          *services.CreditService
+	ledger          *services.LedgerService
+	economy         *services.EconomyService
+	earning         *services.EarningService
+	leaderboard     *services.LeaderboardService
+	profile         *services.ProfileService
+	history         *services.HistoryService
+	season          *services.SeasonService
+	pinned          *services.PinnedService
+	membership      *services.MembershipService
 	activityService *services.ActivityService
 }
 
-func NewMessageHandler(bot *tgbotapi.BotAPI, cfg *config.Config, credit *services.CreditService, activityService *services.ActivityService) *MessageHandler {
-	return &MessageHandler{
+func NewMessageHandler(bot *tgbotapi.BotAPI, cfg *config.Config, credit *services.CreditService, ledger *services.LedgerService, economy *services.EconomyService, earning *services.EarningService, leaderboard *services.LeaderboardService, profile *services.ProfileService, history *services.HistoryService, season *services.SeasonService, pinned *services.PinnedService, membership *services.MembershipService, activityService *services.ActivityService) *MessageHandler {
+	h := &MessageHandler{
 		bot:             bot,
 		config:          cfg,
 		credit:          credit,
+		ledger:          ledger,
+		economy:         economy,
+		earning:         earning,
+		leaderboard:     leaderboard,
+		profile:         profile,
+		history:         history,
+		season:          season,
+		pinned:          pinned,
+		membership:      membership,
 		activityService: activityService,
 	}
+	pinned.OnRefresh(h.refreshPinnedBoard)
+	return h
 }
 
 func (h *MessageHandler) HandleMessage(update tgbotapi.Update) {
 	if update.CallbackQuery != nil {
-		if update.CallbackQuery.Data[:6] == "alive_" {
-			userID := update.CallbackQuery.From.ID
-			username := update.CallbackQuery.From.UserName
-			h.activityService.HandleAliveResponse(userID, username)
-
-			// Get user's credit info to show their alive score
-			userCredit, err := h.credit.GetUserCredit(int(userID))
-			if err != nil {
-				log.Printf("Error getting user credit: %v", err)
-				return
-			}
-
-			// Send response message
-			responseText := fmt.Sprintf("✅ حضور شما ثبت شد!\nامتیاز زنده بودن شما: %d", userCredit.AliveScore)
-			msg := tgbotapi.NewMessage(update.CallbackQuery.Message.Chat.ID, responseText)
-			h.bot.Send(msg)
+		h.handleCallbackQuery(update)
+		return
+	}
 
-			// Remove the "loading" state from the button
-			callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
-			h.bot.Request(callback)
+	if update.InlineQuery != nil {
+		h.handleInlineQuery(update.InlineQuery)
+		return
+	}
 
-			// Edit the original message to show it's been answered
-			editMsg := tgbotapi.NewEditMessageText(
-				update.CallbackQuery.Message.Chat.ID,
-				update.CallbackQuery.Message.MessageID,
-				"هی! زنده‌ای هنوز؟ ✅ بله!",
-			)
-			h.bot.Send(editMsg)
-			return
-		}
+	if update.ChatMember != nil {
+		h.handleChatMemberUpdate(update.ChatMember)
+		return
 	}
 
 	if update.Message == nil {
@@ -88,6 +94,19 @@ func (h *MessageHandler) HandleMessage(update tgbotapi.Update) {
 		}
 	}
 
+	h.trackMembership(update.Message)
+
+	// Answers count as being seen too, so they are recorded before returning
+	answered := update.Message.Chat.IsPrivate() && update.Message.Text != "" && !update.Message.IsCommand() &&
+		h.handleTypedAnswer(update.Message)
+
+	if update.Message.From != nil && !update.Message.From.IsBot {
+		h.activityService.HandleUserMessage(update.Message.From.ID, update.Message.From.UserName)
+	}
+	if answered {
+		return
+	}
+
 	if update.Message.ReplyToMessage != nil && update.Message.Sticker != nil {
 		h.handleStickerReply(update)
 		return
@@ -98,11 +117,100 @@ func (h *MessageHandler) HandleMessage(update tgbotapi.Update) {
 	}
 }
 
+func (h *MessageHandler) handleCallbackQuery(update tgbotapi.Update) {
+	data := update.CallbackQuery.Data
+	switch {
+	case strings.HasPrefix(data, "alive_"):
+		h.handleAliveCallback(update)
+	case strings.HasPrefix(data, leaderboardCallbackPrefix):
+		h.handleLeaderboardCallback(update.CallbackQuery)
+	}
+}
+
+func (h *MessageHandler) handleAliveCallback(update tgbotapi.Update) {
+	userID := update.CallbackQuery.From.ID
+	username := update.CallbackQuery.From.UserName
+
+	// The data is alive_<user ID>, followed by _<option token> for challenges
+	parts := strings.SplitN(strings.TrimPrefix(update.CallbackQuery.Data, "alive_"), "_", 2)
+	answer := ""
+	if len(parts) == 2 {
+		answer = parts[1]
+	}
+	if parts[0] != strconv.FormatInt(userID, 10) {
+		h.bot.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+		return
+	}
+
+	result := h.activityService.HandleAliveResponse(userID, username, answer)
+	switch result {
+	case services.AliveWrong:
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, "❌ اشتباه! دوباره امتحان کن."))
+		return
+	case services.AliveExhausted:
+		h.bot.Request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, "❌ دیگه فرصتی برای این سؤال نداری."))
+		return
+	}
+
+	// Remove the "loading" state from the button
+	callback := tgbotapi.NewCallback(update.CallbackQuery.ID, "")
+	h.bot.Request(callback)
+
+	// Edit the original message to show it's been answered
+	editMsg := tgbotapi.NewEditMessageText(
+		update.CallbackQuery.Message.Chat.ID,
+		update.CallbackQuery.Message.MessageID,
+		"هی! زنده‌ای هنوز؟ ✅ بله!",
+	)
+	h.bot.Send(editMsg)
+
+	// Answers to checks that are no longer pending earn nothing
+	if result != services.AliveAccepted {
+		return
+	}
+	h.sendAliveScore(update.CallbackQuery.Message.Chat.ID, userID)
+}
+
+// handleTypedAnswer checks a private message against a pending typed word
+// challenge. It returns true if the message was an answer.
+func (h *MessageHandler) handleTypedAnswer(message *tgbotapi.Message) bool {
+	result := h.activityService.HandleTypedAnswer(message.From.ID, message.From.UserName, message.Text)
+	switch result {
+	case services.AliveIgnored:
+		return false
+	case services.AliveWrong:
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ اشتباه! دوباره امتحان کن."))
+	case services.AliveExhausted:
+		h.bot.Send(tgbotapi.NewMessage(message.Chat.ID, "❌ دیگه فرصتی برای این سؤال نداری."))
+	case services.AliveAccepted:
+		h.sendAliveScore(message.Chat.ID, message.From.ID)
+	}
+	return true
+}
+
+func (h *MessageHandler) sendAliveScore(chatID, userID int64) {
+	// Get user's credit info to show their alive score
+	userCredit, err := h.credit.GetUserCredit(int(userID))
+	if err != nil {
+		log.Printf("Error getting user credit: %v", err)
+		return
+	}
+
+	// Send response message
+	responseText := fmt.Sprintf("✅ حضور شما ثبت شد!\nامتیاز زنده بودن شما: %d", userCredit.AliveScore)
+	msg := tgbotapi.NewMessage(chatID, responseText)
+	h.bot.Send(msg)
+}
+
 func (h *MessageHandler) handleStickerReply(update tgbotapi.Update) {
 	if update.Message.From.ID == update.Message.ReplyToMessage.From.ID {
 		if h.handleSelfReplyFraud(update) {
 			return
 		}
+		// Money sent to yourself would go nowhere
+		if h.isTransferSticker(update.Message.Sticker.FileUniqueID) {
+			return
+		}
 	}
 
 	if h.isTransferSticker(update.Message.Sticker.FileUniqueID) {
@@ -113,21 +221,36 @@ func (h *MessageHandler) handleStickerReply(update tgbotapi.Update) {
 	h.handleSocialCredit(update)
 }
 
+// handleSelfReplyFraud punishes a positive sticker on the sender's own
+// message and returns true if the sticker was one, even when the penalty
+// could not be applied, so it is never counted as a vote.
 func (h *MessageHandler) handleSelfReplyFraud(update tgbotapi.Update) bool {
-	if h.getStickerType(update.Message.Sticker.FileUniqueID) != "positive" {
+	if h.getStickerType(update.Message.Sticker.FileUniqueID) != models.CreditPositive {
 		return false
 	}
 
 	cheater, err := h.credit.GetUserCredit(int(update.Message.From.ID))
 	if err != nil {
 		log.Printf("Error getting user credit: %v", err)
-		return false
+		return true
 	}
 
-	h.credit.AddCredit(int(update.Message.From.ID), -3)
-	msgText := fmt.Sprintf("🚫 Fraud detected! @%s tried to cheat by replying to their own message with a positive sticker.\nPenalty: -3 SocialCredit\nCurrent balance: %d",
+	penalty, err := h.credit.Vote(update.Message.From.ID, update.Message.From.ID, update.Message.Chat.ID, models.CreditFraud)
+	if err != nil {
+		log.Printf("Error applying fraud penalty: %v", err)
+		return true
+	}
+	fine, err := h.credit.FineFraud(update.Message.From.ID, update.Message.Chat.ID)
+	if err != nil {
+		log.Printf("Error collecting fraud fine: %v", err)
+	}
+	msgText := fmt.Sprintf("🚫 Fraud detected! @%s tried to cheat by replying to their own message with a positive sticker.\nPenalty: %d SocialCredit\nCurrent balance: %d",
 		cheater.Username,
-		cheater.Credit-3)
+		penalty,
+		cheater.Credit+penalty)
+	if fine > 0 {
+		msgText += fmt.Sprintf("\nFine: %d money paid to the treasury", fine)
+	}
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
 	h.bot.Send(msg)
 	return true
@@ -138,12 +261,19 @@ func (h *MessageHandler) isTransferSticker(fileUniqueID string) bool {
 }
 
 func (h *MessageHandler) handleMoneyTransfer(update tgbotapi.Update) {
-	err := h.credit.TransferMoney(
+	receipt, err := h.credit.TransferMoney(
 		int(update.Message.From.ID),
 		int(update.Message.ReplyToMessage.From.ID),
+		update.Message.Chat.ID,
 	)
+	if errors.Is(err, services.ErrInsufficientBalance) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ You don't have enough money to transfer!")
+		h.bot.Send(msg)
+		return
+	}
 	if err != nil {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ You don't have any money to transfer!")
+		log.Printf("Error transferring money: %v", err)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ Transfer failed!")
 		h.bot.Send(msg)
 		return
 	}
@@ -151,9 +281,14 @@ func (h *MessageHandler) handleMoneyTransfer(update tgbotapi.Update) {
 	sender, _ := h.credit.GetUserCredit(int(update.Message.From.ID))
 	receiver, _ := h.credit.GetUserCredit(int(update.Message.ReplyToMessage.From.ID))
 
-	msgText := fmt.Sprintf("💰 Money Transfer:\n@%s sent 1 money to @%s\n\n@%s's balance: %d\n@%s's balance: %d",
+	msgText := fmt.Sprintf("💰 Money Transfer:\n@%s sent %d money to @%s\n",
 		sender.Username,
-		receiver.Username,
+		receipt.Amount,
+		receiver.Username)
+	if receipt.Tax > 0 {
+		msgText += fmt.Sprintf("Tax withheld: %d\n", receipt.Tax)
+	}
+	msgText += fmt.Sprintf("\n@%s's balance: %d\n@%s's balance: %d",
 		sender.Username,
 		sender.Money,
 		receiver.Username,
@@ -168,18 +303,17 @@ func (h *MessageHandler) handleSocialCredit(update tgbotapi.Update) {
 		return
 	}
 
-	amount := 1
-	if stickerType == "negative" {
-		amount = -1
-	}
-
 	user, err := h.credit.GetUserCredit(int(update.Message.ReplyToMessage.From.ID))
 	if err != nil {
 		log.Printf("Error getting user credit: %v", err)
 		return
 	}
 
-	h.credit.AddCredit(int(update.Message.ReplyToMessage.From.ID), amount)
+	amount, err := h.credit.Vote(update.Message.From.ID, update.Message.ReplyToMessage.From.ID, update.Message.Chat.ID, stickerType)
+	if err != nil {
+		log.Printf("Error applying vote: %v", err)
+		return
+	}
 	msgText := fmt.Sprintf("@%s got %+d SocialCredit! Total: %d",
 		user.Username,
 		amount,
@@ -190,69 +324,101 @@ func (h *MessageHandler) handleSocialCredit(update tgbotapi.Update) {
 
 func (h *MessageHandler) getStickerType(fileUniqueID string) string {
 	if slices.Contains(h.config.App.Stickers.Positive, fileUniqueID) {
-		return "positive"
+		return models.CreditPositive
 	}
 	if slices.Contains(h.config.App.Stickers.Negative, fileUniqueID) {
-		return "negative"
+		return models.CreditNegative
 	}
 	return ""
 }
 
 func (h *MessageHandler) handleCommand(update tgbotapi.Update) {
 	switch update.Message.Command() {
-	case "credits":
-		h.handleCreditsCommand(update)
-	case "money":
-		h.handleMoneyCommand(update)
 	case "alive":
-		h.handleAliveCommand(update)
+		if args := strings.Fields(update.Message.CommandArguments()); len(args) > 0 && strings.EqualFold(args[0], "stats") {
+			h.handleAliveStatsCommand(update)
+		} else {
+			h.handleLeaderboardCommand(update)
+		}
+	case "credits", "money", "shame", "fallers", "haters":
+		h.handleLeaderboardCommand(update)
+	case "daily":
+		h.handleDailyCommand(update)
+	case "work":
+		h.handleWorkCommand(update)
+	case "economy":
+		h.handleEconomyCommand(update)
+	case "history":
+		h.handleHistoryCommand(update)
+	case "profile":
+		h.handleProfileCommand(update)
+	case "treasury":
+		h.handleTreasuryCommand(update)
+	case "grant":
+		h.handleGrantCommand(update)
+	case "audit":
+		h.handleAuditCommand(update)
+	case "season":
+		h.handleSeasonCommand(update)
+	case "halloffame":
+		h.handleHallOfFameCommand(update)
+	case "endseason":
+		h.handleEndSeasonCommand(update)
+	case "pinboard":
+		h.handlePinBoardCommand(update)
+	case "unpinboard":
+		h.handleUnpinBoardCommand(update)
+	case "snooze":
+		h.handleSnoozeCommand(update)
+	case "optout":
+		h.handleOptOutCommand(update)
+	case "optin":
+		h.handleOptInCommand(update)
+	case "snoozed":
+		h.handleSnoozedCommand(update)
+	case "timezone":
+		h.handleTimeZoneCommand(update)
+	case "quiethours":
+		h.handleQuietHoursCommand(update)
 	}
 }
 
-func (h *MessageHandler) handleCreditsCommand(update tgbotapi.Update) {
-	credits, err := h.credit.GetTopCredits(10)
-	if err != nil {
-		log.Printf("Error getting top credits: %v", err)
-		return
-	}
-
-	text := "🌟 SocialCredit Leaderboard:\n"
-	for _, credit := range credits {
-		text += fmt.Sprintf("@%s — %d\n", credit.Username, credit.Credit)
-	}
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
-	h.bot.Send(msg)
+func (h *MessageHandler) isAdmin(userID int64) bool {
+	return slices.Contains(h.config.App.Admins, userID)
 }
 
-func (h *MessageHandler) handleMoneyCommand(update tgbotapi.Update) {
-	credits, err := h.credit.GetTopMoney(10)
-	if err != nil {
-		log.Printf("Error getting top money: %v", err)
-		return
-	}
+var errNoTarget = errors.New("no target user")
 
-	text := "💰 Money Leaderboard:\n"
-	for _, credit := range credits {
-		text += fmt.Sprintf("@%s — %d\n", credit.Username, credit.Money)
+// resolveTarget finds the user a command is about: the author of the replied
+// message, or the @username given as the first argument. It returns the
+// remaining arguments.
+func (h *MessageHandler) resolveTarget(update tgbotapi.Update, args []string) (*models.Credit, []string, error) {
+	if update.Message.ReplyToMessage != nil && update.Message.ReplyToMessage.From != nil {
+		user, err := h.credit.GetUserCredit(int(update.Message.ReplyToMessage.From.ID))
+		return user, args, err
 	}
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
-	h.bot.Send(msg)
+	if len(args) > 0 && strings.HasPrefix(args[0], "@") {
+		user, err := h.credit.GetUserByUsername(strings.TrimPrefix(args[0], "@"))
+		return user, args[1:], err
+	}
+	return nil, args, errNoTarget
 }
 
-func (h *MessageHandler) handleAliveCommand(update tgbotapi.Update) {
-	credits, err := h.credit.GetTopAliveScores(10)
-	if err != nil {
-		log.Printf("Error getting top alive scores: %v", err)
+func (h *MessageHandler) handleAuditCommand(update tgbotapi.Update) {
+	if !h.isAdmin(update.Message.From.ID) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "⛔ This command is for admins only.")
+		h.bot.Send(msg)
 		return
 	}
 
-	text := "🌟 امتیاز زنده بودن:\n"
-	for _, credit := range credits {
-		text += fmt.Sprintf("@%s — %d\n", credit.Username, credit.AliveScore)
+	report, err := h.ledger.Audit()
+	if err != nil {
+		log.Printf("Error running audit: %v", err)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ The audit failed, see the logs for details.")
+		h.bot.Send(msg)
+		return
 	}
 
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, report.String())
 	h.bot.Send(msg)
 }
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"social-credit/internal/models"
+	"social-credit/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const defaultPinnedBoard = "credits"
+
+// handlePinBoardCommand posts a leaderboard, pins it and keeps it up to date
+// from then on. It replaces the chat's previous pinned leaderboard.
+func (h *MessageHandler) handlePinBoardCommand(update tgbotapi.Update) {
+	if !h.isAdmin(update.Message.From.ID) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "⛔ This command is for admins only.")
+		h.bot.Send(msg)
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	if memberChatID(update.Message.Chat) == 0 {
+		msg := tgbotapi.NewMessage(chatID, "📌 Leaderboards can only be pinned in groups.")
+		h.bot.Send(msg)
+		return
+	}
+	name := strings.ToLower(strings.TrimSpace(update.Message.CommandArguments()))
+	if name == "" {
+		name = defaultPinnedBoard
+	}
+	if _, ok := leaderboards[name]; !ok {
+		msg := tgbotapi.NewMessage(chatID, "Usage: /pinboard [credits|money|alive|shame|fallers|haters]")
+		h.bot.Send(msg)
+		return
+	}
+
+	text, err := h.renderPinnedBoard(name, chatID)
+	if err != nil {
+		log.Printf("Error getting %s leaderboard: %v", name, err)
+		return
+	}
+	sent, err := h.bot.Send(tgbotapi.NewMessage(chatID, text))
+	if err != nil {
+		log.Printf("Error sending pinned leaderboard: %v", err)
+		return
+	}
+	pin := tgbotapi.PinChatMessageConfig{ChatID: chatID, MessageID: sent.MessageID, DisableNotification: true}
+	if _, err := h.bot.Request(pin); err != nil {
+		log.Printf("Error pinning leaderboard: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "❌ I need permission to pin messages in this chat.")
+		h.bot.Send(msg)
+		return
+	}
+
+	previous, err := h.pinned.Get(chatID)
+	if err != nil {
+		log.Printf("Error getting pinned leaderboard: %v", err)
+	}
+	if previous != nil {
+		h.bot.Request(tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: previous.MessageID})
+	}
+	if err := h.pinned.Enable(chatID, sent.MessageID, name); err != nil {
+		log.Printf("Error saving pinned leaderboard: %v", err)
+	}
+}
+
+// handleUnpinBoardCommand stops updating the chat's pinned leaderboard and
+// unpins it.
+func (h *MessageHandler) handleUnpinBoardCommand(update tgbotapi.Update) {
+	if !h.isAdmin(update.Message.From.ID) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "⛔ This command is for admins only.")
+		h.bot.Send(msg)
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	board, err := h.pinned.Get(chatID)
+	if err != nil {
+		log.Printf("Error getting pinned leaderboard: %v", err)
+		return
+	}
+	if board == nil {
+		msg := tgbotapi.NewMessage(chatID, "There is no pinned leaderboard in this chat.")
+		h.bot.Send(msg)
+		return
+	}
+
+	if err := h.pinned.Disable(chatID); err != nil {
+		log.Printf("Error removing pinned leaderboard: %v", err)
+		return
+	}
+	h.bot.Request(tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: board.MessageID})
+	msg := tgbotapi.NewMessage(chatID, "📌 The leaderboard is no longer pinned.")
+	h.bot.Send(msg)
+}
+
+// refreshPinnedBoard redraws a pinned leaderboard. It is called by the pinned
+// service after balances change.
+func (h *MessageHandler) refreshPinnedBoard(board models.PinnedBoard) {
+	text, err := h.renderPinnedBoard(board.Board, board.ChatID)
+	if err != nil {
+		log.Printf("Error getting %s leaderboard: %v", board.Board, err)
+		return
+	}
+
+	_, err = h.bot.Send(tgbotapi.NewEditMessageText(board.ChatID, board.MessageID, text))
+	if err == nil || strings.Contains(err.Error(), "message is not modified") {
+		return
+	}
+	if strings.Contains(err.Error(), "message to edit not found") {
+		// Somebody deleted the message, so there is nothing left to update
+		if err := h.pinned.Disable(board.ChatID); err != nil {
+			log.Printf("Error removing pinned leaderboard: %v", err)
+		}
+		return
+	}
+	log.Printf("Error updating pinned leaderboard in chat %d: %v", board.ChatID, err)
+}
+
+func (h *MessageHandler) renderPinnedBoard(name string, chatID int64) (string, error) {
+	req := leaderboardRequest{name: name, window: services.WindowAll, size: defaultPageSize, chatID: chatID}
+	if window := leaderboards[name].window; window != "" {
+		req.window = window
+	}
+	text, _, err := h.renderLeaderboard(req, 0)
+	if err != nil {
+		return "", err
+	}
+	return text + "\n📌 Live leaderboard, updated " + time.Now().UTC().Format("15:04") + " UTC", nil
+}
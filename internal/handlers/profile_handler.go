@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"social-credit/internal/models"
+	"social-credit/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleProfileCommand shows the profile of the replied-to user, the given
+// @username, or the sender.
+func (h *MessageHandler) handleProfileCommand(update tgbotapi.Update) {
+	userID := update.Message.From.ID
+	user, _, err := h.resolveTarget(update, strings.Fields(update.Message.CommandArguments()))
+	if err == nil {
+		userID = int64(user.UserID)
+	} else if !errors.Is(err, errNoTarget) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ I don't know that citizen.")
+		h.bot.Send(msg)
+		return
+	}
+
+	profile, err := h.profile.GetProfile(userID, memberChatID(update.Message.Chat))
+	if err != nil {
+		log.Printf("Error getting profile: %v", err)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, formatProfile(profile))
+	h.bot.Send(msg)
+}
+
+func formatProfile(p *services.Profile) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🪪 Citizen @%s\n", p.Credit.Username)
+	if p.Tier != "" {
+		fmt.Fprintf(&b, "🏅 Tier: %s\n", p.Tier)
+	}
+	if len(p.Badges) > 0 {
+		b.WriteString("🎖️ Badges:")
+		for _, badge := range p.Badges {
+			b.WriteString(" " + badge.Emoji)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "🌟 SocialCredit: %d%s\n", p.Credit.Credit, formatRank(p.Ranks[services.BoardCredit]))
+	fmt.Fprintf(&b, "💰 Money: %d%s\n", p.Credit.Money, formatRank(p.Ranks[services.BoardMoney]))
+	fmt.Fprintf(&b, "🟢 Alive score: %d%s\n", p.Credit.AliveScore, formatRank(p.Ranks[services.BoardAlive]))
+	fmt.Fprintf(&b, "🗳️ Votes given: 👍 %d 👎 %d\n", p.VotesGiven.Positive, p.VotesGiven.Negative)
+	fmt.Fprintf(&b, "📬 Votes received: 👍 %d 👎 %d\n", p.VotesReceived.Positive, p.VotesReceived.Negative)
+	if p.LastResponse.IsZero() {
+		b.WriteString("⏱️ Last activity check: never answered\n")
+	} else {
+		fmt.Fprintf(&b, "⏱️ Last activity check: %s\n", p.LastResponse.Format("2006-01-02 15:04"))
+	}
+	switch p.ActivityState {
+	case models.ActivitySnoozed:
+		fmt.Fprintf(&b, "😴 Activity checks snoozed until %s\n", p.SnoozedUntil.Format("2006-01-02 15:04"))
+	case models.ActivityOptedOut:
+		b.WriteString("🚫 Opted out of activity checks\n")
+	case models.ActivityInactive:
+		b.WriteString("💀 Inactive\n")
+	}
+	if p.Credit.CreatedAt.IsZero() {
+		b.WriteString("📅 Citizen since before records began\n")
+	} else {
+		fmt.Fprintf(&b, "📅 Citizen for %d day(s)\n", int(time.Since(p.Credit.CreatedAt).Hours()/24))
+	}
+	return b.String()
+}
+
+func formatRank(rank int) string {
+	if rank == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (#%d)", rank)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"social-credit/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	hallOfFameSeasons   = 5
+	hallOfFameFinishers = 3
+)
+
+// handleSeasonCommand shows the running season and when it ends.
+func (h *MessageHandler) handleSeasonCommand(update tgbotapi.Update) {
+	season, err := h.season.Current()
+	if err != nil {
+		log.Printf("Error getting current season: %v", err)
+		return
+	}
+
+	text := fmt.Sprintf("🏆 Season %d\n📅 Started: %s\n", season.Number, season.StartedAt.Format("2006-01-02"))
+	if season.EndsAt.IsZero() {
+		text += "⏳ Ends when an admin ends it\n"
+	} else {
+		days := int(time.Until(season.EndsAt).Hours() / 24)
+		text += fmt.Sprintf("⏳ Ends: %s (%d day(s) left)\n", season.EndsAt.Format("2006-01-02"), max(days, 0))
+	}
+	if percent := h.config.App.Seasons.ResetPercent; percent > 0 {
+		text += fmt.Sprintf("🔄 %d%% of SocialCredit is reset when the season ends\n", percent)
+	}
+	if badges := h.config.App.Seasons.Badges; len(badges) > 0 {
+		text += fmt.Sprintf("🎖️ Badges for the top %d: %s\n", len(badges), strings.Join(badges, " "))
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	h.bot.Send(msg)
+}
+
+// handleHallOfFameCommand shows the top finishers of the latest seasons.
+func (h *MessageHandler) handleHallOfFameCommand(update tgbotapi.Update) {
+	results, err := h.season.HallOfFame(hallOfFameSeasons, max(len(h.config.App.Seasons.Badges), hallOfFameFinishers))
+	if err != nil {
+		log.Printf("Error getting hall of fame: %v", err)
+		return
+	}
+
+	text := "🏛️ Hall of Fame:\n"
+	if len(results) == 0 {
+		text += "No season has ended yet."
+	}
+	for _, result := range results {
+		text += fmt.Sprintf("\nSeason %d (%s – %s)\n",
+			result.Season.Number,
+			result.Season.StartedAt.Format("2006-01-02"),
+			result.Season.EndedAt.Format("2006-01-02"))
+		for _, standing := range result.Standings {
+			text += fmt.Sprintf("%d. @%s — %d\n", standing.Rank, standing.Username, standing.Credit)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	h.bot.Send(msg)
+}
+
+// handleEndSeasonCommand lets an admin end the running season early.
+func (h *MessageHandler) handleEndSeasonCommand(update tgbotapi.Update) {
+	if !h.isAdmin(update.Message.From.ID) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "⛔ This command is for admins only.")
+		h.bot.Send(msg)
+		return
+	}
+
+	result, err := h.season.EndSeason()
+	if err != nil {
+		log.Printf("Error ending season: %v", err)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ Failed to end the season!")
+		h.bot.Send(msg)
+		return
+	}
+
+	text := services.FormatSeasonResult(result, len(h.config.App.Seasons.Badges))
+	text += fmt.Sprintf("\n🏆 Season %d has begun!", result.Season.Number+1)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	h.bot.Send(msg)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"social-credit/internal/models"
+	"social-credit/internal/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const treasuryHistoryLimit = 10
+
+func (h *MessageHandler) handleTreasuryCommand(update tgbotapi.Update) {
+	treasury := services.TreasuryAccount(update.Message.Chat.ID)
+	balance, err := h.ledger.Balance(treasury)
+	if err != nil {
+		log.Printf("Error getting treasury balance: %v", err)
+		return
+	}
+	lines, err := h.ledger.Statement(treasury, treasuryHistoryLimit)
+	if err != nil {
+		log.Printf("Error getting treasury history: %v", err)
+		return
+	}
+
+	text := fmt.Sprintf("🏛️ Treasury balance: %d\n", balance)
+	if len(lines) > 0 {
+		text += "\nRecent activity:\n"
+	}
+	for _, line := range lines {
+		text += fmt.Sprintf("%s %+d %s (%s)",
+			line.Entry.CreatedAt.Format("2006-01-02"),
+			line.Amount,
+			h.accountName(line.Counterparty),
+			line.Entry.Kind)
+		if line.Entry.Memo != "" {
+			text += " — " + line.Entry.Memo
+		}
+		text += "\n"
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	h.bot.Send(msg)
+}
+
+func (h *MessageHandler) handleGrantCommand(update tgbotapi.Update) {
+	if !h.isAdmin(update.Message.From.ID) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "⛔ This command is for admins only.")
+		h.bot.Send(msg)
+		return
+	}
+
+	usage := "Usage: /grant @username <amount> [reason], or reply to a message with /grant <amount> [reason]"
+	user, args, err := h.resolveTarget(update, strings.Fields(update.Message.CommandArguments()))
+	if err != nil || len(args) == 0 {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, usage)
+		h.bot.Send(msg)
+		return
+	}
+	amount, err := strconv.Atoi(args[0])
+	if err != nil || amount <= 0 {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, usage)
+		h.bot.Send(msg)
+		return
+	}
+	reason := strings.Join(args[1:], " ")
+
+	err = h.ledger.Move(
+		services.TreasuryAccount(update.Message.Chat.ID),
+		services.UserAccount(int64(user.UserID)),
+		amount,
+		models.EntryGrant,
+		reason,
+	)
+	if errors.Is(err, services.ErrInsufficientBalance) {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "❌ The treasury doesn't have that much money!")
+		h.bot.Send(msg)
+		return
+	}
+	if err != nil {
+		log.Printf("Error granting from treasury: %v", err)
+		return
+	}
+	h.pinned.Touch(update.Message.Chat.ID)
+
+	msgText := fmt.Sprintf("🏛️ The treasury granted %d money to @%s", amount, user.Username)
+	if reason != "" {
+		msgText += fmt.Sprintf("\nReason: %s", reason)
+	}
+	msgText += fmt.Sprintf("\n@%s's balance: %d", user.Username, user.Money+amount)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+	h.bot.Send(msg)
+}
+
+// accountName returns a human readable name for a ledger account.
+func (h *MessageHandler) accountName(account models.Account) string {
+	if account.Kind != models.AccountUser {
+		return account.Kind
+	}
+	user, err := h.credit.GetUserCredit(int(account.OwnerID))
+	if err != nil {
+		return strconv.FormatInt(account.OwnerID, 10)
+	}
+	return "@" + user.Username
+}
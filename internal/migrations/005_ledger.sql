@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS accounts (
+    id SERIAL PRIMARY KEY,
+    kind TEXT NOT NULL,
+    owner_id BIGINT NOT NULL,
+    balance INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_kind_owner ON accounts(kind, owner_id);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id SERIAL PRIMARY KEY,
+    from_account_id BIGINT NOT NULL,
+    to_account_id BIGINT NOT NULL,
+    amount INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    memo TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_from_account_id ON ledger_entries(from_account_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_to_account_id ON ledger_entries(to_account_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_kind ON ledger_entries(kind);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_created_at ON ledger_entries(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS accounts;
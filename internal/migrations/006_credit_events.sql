@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS credit_events (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    actor_id BIGINT NOT NULL,
+    chat_id BIGINT NOT NULL,
+    kind TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_events_user_id ON credit_events(user_id);
+CREATE INDEX IF NOT EXISTS idx_credit_events_actor_id ON credit_events(actor_id);
+CREATE INDEX IF NOT EXISTS idx_credit_events_created_at ON credit_events(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS credit_events;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS earnings (
+    user_id BIGINT PRIMARY KEY,
+    last_daily TIMESTAMP,
+    daily_streak INTEGER NOT NULL DEFAULT 0,
+    last_work TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS earnings;
@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS seasons (
+    id SERIAL PRIMARY KEY,
+    number INTEGER NOT NULL UNIQUE,
+    started_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+    ended_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS season_standings (
+    id SERIAL PRIMARY KEY,
+    season_id BIGINT NOT NULL REFERENCES seasons(id),
+    user_id BIGINT NOT NULL,
+    username TEXT NOT NULL,
+    rank INTEGER NOT NULL,
+    credit INTEGER NOT NULL,
+    money INTEGER NOT NULL,
+    alive_score INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_season_standings_season_id ON season_standings(season_id);
+CREATE INDEX IF NOT EXISTS idx_season_standings_user_id ON season_standings(user_id);
+
+CREATE TABLE IF NOT EXISTS badges (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    season_id BIGINT NOT NULL REFERENCES seasons(id),
+    rank INTEGER NOT NULL,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_badges_user_id ON badges(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS badges;
+DROP TABLE IF EXISTS season_standings;
+DROP TABLE IF EXISTS seasons;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS pinned_boards (
+    chat_id BIGINT PRIMARY KEY,
+    message_id INTEGER NOT NULL,
+    board TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS pinned_boards;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- 004 named the table activity_status, the bot reads activity_statuses
+ALTER TABLE IF EXISTS activity_status RENAME TO activity_statuses;
+
+UPDATE activity_statuses SET next_check_time = '0001-01-01 00:00:00'
+WHERE last_response >= last_check;
+
+CREATE INDEX IF NOT EXISTS idx_activity_statuses_next_check_time ON activity_statuses(next_check_time);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_activity_statuses_next_check_time;
+ALTER TABLE activity_statuses RENAME TO activity_status;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- The bot derives the state of existing rows from is_active and drops
+-- is_active when it starts
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'idle';
+
+CREATE INDEX IF NOT EXISTS idx_activity_statuses_state ON activity_statuses(state);
+
+CREATE TABLE IF NOT EXISTS activity_transitions (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    from_state TEXT NOT NULL,
+    to_state TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_transitions_user_id ON activity_transitions(user_id);
+CREATE INDEX IF NOT EXISTS idx_activity_transitions_created_at ON activity_transitions(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS activity_transitions;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT true;
+UPDATE activity_statuses SET is_active = state <> 'inactive';
+DROP INDEX IF EXISTS idx_activity_statuses_state;
+ALTER TABLE activity_statuses DROP COLUMN state;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS snoozed_until TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE activity_statuses DROP COLUMN snoozed_until;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS ping_at TIMESTAMP;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS time_zone TEXT;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS quiet_start INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS quiet_end INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_activity_statuses_ping_at ON activity_statuses(ping_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_activity_statuses_ping_at;
+ALTER TABLE activity_statuses DROP COLUMN quiet_end;
+ALTER TABLE activity_statuses DROP COLUMN quiet_start;
+ALTER TABLE activity_statuses DROP COLUMN time_zone;
+ALTER TABLE activity_statuses DROP COLUMN ping_at;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS challenge TEXT;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS challenge_answer TEXT;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS failed_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE activity_checks ADD COLUMN IF NOT EXISTS failed_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE activity_checks DROP COLUMN failed_attempts;
+ALTER TABLE activity_statuses DROP COLUMN failed_attempts;
+ALTER TABLE activity_statuses DROP COLUMN challenge_answer;
+ALTER TABLE activity_statuses DROP COLUMN challenge;
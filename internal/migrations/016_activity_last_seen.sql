@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS last_seen TIMESTAMP;
+ALTER TABLE activity_checks ADD COLUMN IF NOT EXISTS passive BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE activity_checks DROP COLUMN passive;
+ALTER TABLE activity_statuses DROP COLUMN last_seen;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS streak INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS best_streak INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE activity_statuses DROP COLUMN best_streak;
+ALTER TABLE activity_statuses DROP COLUMN streak;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE activity_checks ADD COLUMN IF NOT EXISTS ping_time TIMESTAMP;
+ALTER TABLE activity_checks ADD COLUMN IF NOT EXISTS latency INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE activity_checks DROP COLUMN latency;
+ALTER TABLE activity_checks DROP COLUMN ping_time;
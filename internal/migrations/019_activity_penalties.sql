@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS penalized_at TIMESTAMP;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS penalty_credit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE activity_statuses ADD COLUMN IF NOT EXISTS penalty_money INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE activity_statuses DROP COLUMN penalty_money;
+ALTER TABLE activity_statuses DROP COLUMN penalty_credit;
+ALTER TABLE activity_statuses DROP COLUMN penalized_at;
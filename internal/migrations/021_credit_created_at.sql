@@ -0,0 +1,6 @@
+-- +goose Up
+-- Users who joined before this column have no known join date and stay NULL
+ALTER TABLE credits ADD COLUMN IF NOT EXISTS created_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE credits DROP COLUMN created_at;
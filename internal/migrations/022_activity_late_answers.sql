@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE activity_checks ADD COLUMN IF NOT EXISTS late BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE activity_checks DROP COLUMN late;
@@ -4,18 +4,75 @@ import (
 	"time"
 )
 
+// Activity check states
+const (
+	// ActivityIdle users have no check waiting for an answer
+	ActivityIdle = "idle"
+	// ActivityPending users were pinged and have not answered yet
+	ActivityPending = "pending"
+	// ActivityWarned users missed at least one deadline and were pinged again
+	ActivityWarned = "warned"
+	// ActivityInactive users missed every retry
+	ActivityInactive = "inactive"
+	// ActivitySnoozed users are not checked for a while
+	ActivitySnoozed = "snoozed"
+	// ActivityOptedOut users are never checked
+	ActivityOptedOut = "opted_out"
+)
+
 // ActivityStatus represents the current status of a user's activity check
 type ActivityStatus struct {
-	UserID        int64     `gorm:"primaryKey"`
-	Username      string    `gorm:"not null"`
-	LastCheck     time.Time `gorm:"not null"`
-	LastResponse  time.Time
-	RetryCount    int  `gorm:"not null;default:0"`
-	IsActive      bool `gorm:"not null;default:true"`
-	MessageID     int
-	NextCheckTime time.Time `gorm:"not null"`
-	CreatedAt     time.Time `gorm:"autoCreateTime"`
-	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+	UserID       int64     `gorm:"primaryKey"`
+	Username     string    `gorm:"not null"`
+	LastCheck    time.Time `gorm:"not null"`
+	LastResponse time.Time
+	// LastSeen is when the user last sent a message the bot saw
+	LastSeen   time.Time
+	State      string `gorm:"not null;default:idle;index"`
+	RetryCount int    `gorm:"not null;default:0"`
+	MessageID  int
+	// Unreachable users cannot be messaged in private, because they never
+	// started the bot or blocked it, and are pinged in the group instead
+	Unreachable bool `gorm:"not null;default:false"`
+	// Challenge is the type of the pending check and ChallengeAnswer its
+	// expected answer, the typed word or the token of the right button.
+	// FailedAttempts counts wrong answers to it.
+	Challenge       string
+	ChallengeAnswer string
+	FailedAttempts  int `gorm:"not null;default:0"`
+	// NextCheckTime is the deadline of the pending check, zero when no check
+	// is waiting for an answer
+	NextCheckTime time.Time `gorm:"not null;index"`
+	// SnoozedUntil is when a snoozed user starts being checked again
+	SnoozedUntil time.Time
+	// PingAt is when a ping deferred by quiet hours is due, zero when none is
+	PingAt time.Time `gorm:"index"`
+	// TimeZone is an IANA time zone name, empty for the configured default
+	TimeZone string
+	// QuietStart and QuietEnd are minutes after local midnight between which
+	// the user is never pinged. Equal values mean no quiet hours.
+	QuietStart int `gorm:"not null;default:0"`
+	QuietEnd   int `gorm:"not null;default:0"`
+	// PenalizedAt is when the user was last penalized for becoming inactive
+	// and PenaltyCredit and PenaltyMoney what they lost, until it is reversed
+	PenalizedAt   time.Time
+	PenaltyCredit int `gorm:"not null;default:0"`
+	PenaltyMoney  int `gorm:"not null;default:0"`
+	// Streak counts consecutive checks answered before their first deadline
+	Streak     int       `gorm:"not null;default:0"`
+	BestStreak int       `gorm:"not null;default:0"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// ActivityTransition records one change of a user's activity check state.
+type ActivityTransition struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UserID    int64     `gorm:"not null;index"`
+	FromState string    `gorm:"not null"`
+	ToState   string    `gorm:"not null"`
+	Reason    string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
 }
 
 // ActivityCheck represents a single activity check instance
@@ -25,6 +82,18 @@ type ActivityCheck struct {
 	Username  string    `gorm:"not null"`
 	CheckTime time.Time `gorm:"not null;index"`
 	Response  bool      `gorm:"not null"`
-	Score     int       `gorm:"not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	// Passive checks were satisfied by chat activity instead of an answer
+	Passive bool `gorm:"not null;default:false"`
+	// Score is the alive score awarded for the check, bonuses included,
+	// which the windowed alive leaderboard sums
+	Score int `gorm:"not null"`
+	// PingTime is when the answered ping was sent and Latency how many
+	// seconds the answer took. Both are zero for missed and passive checks.
+	PingTime time.Time
+	Latency  int `gorm:"not null;default:0"`
+	// Late answers came after the final deadline, from an inactive user
+	Late bool `gorm:"not null;default:false"`
+	// FailedAttempts counts wrong answers given before this check ended
+	FailedAttempts int       `gorm:"not null;default:0"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
 }
@@ -1,9 +1,37 @@
 package models
 
+import (
+	"time"
+)
+
 type Credit struct {
 	UserID     int `gorm:"primaryKey"`
 	Username   string
 	Credit     int
-	Money      int `gorm:"default:0"`
-	AliveScore int `gorm:"default:0"`
+	Money      int       `gorm:"default:0"`
+	AliveScore int       `gorm:"default:0"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// Credit event kinds
+const (
+	CreditPositive = "positive"
+	CreditNegative = "negative"
+	CreditFraud    = "fraud"
+	CreditDecay    = "decay"
+	CreditSeason   = "season_reset"
+	CreditInactive = "inactive"
+	CreditReturned = "inactive_refund"
+)
+
+// CreditEvent records a single SocialCredit change. ActorID is the voter, or
+// 0 for changes made by the system.
+type CreditEvent struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UserID    int64     `gorm:"not null;index"`
+	ActorID   int64     `gorm:"not null;index"`
+	ChatID    int64     `gorm:"not null"`
+	Kind      string    `gorm:"not null"`
+	Amount    int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// Earning keeps the /daily and /work cooldowns of a user so they survive
+// restarts.
+type Earning struct {
+	UserID      int64 `gorm:"primaryKey"`
+	LastDaily   time.Time
+	DailyStreak int `gorm:"not null;default:0"`
+	LastWork    time.Time
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// Account kinds. User accounts are owned by a Telegram user, treasury
+// accounts by a chat (or 0 for the global one), and the mint is the
+// single system account money is created from and burned into.
+const (
+	AccountUser     = "user"
+	AccountMint     = "mint"
+	AccountTreasury = "treasury"
+)
+
+// Ledger entry kinds
+const (
+	EntryOpening  = "opening"
+	EntryGrant    = "grant"
+	EntryTransfer = "transfer"
+	EntryTax      = "tax"
+	EntryDaily    = "daily"
+	EntryWork     = "work"
+	EntryFine     = "fine"
+	EntryRefund   = "refund"
+)
+
+// Account holds a money balance. The balance is a cache of the ledger entries
+// touching the account and is verified by the supply audit.
+type Account struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	Kind      string    `gorm:"not null;uniqueIndex:idx_accounts_kind_owner"`
+	OwnerID   int64     `gorm:"not null;uniqueIndex:idx_accounts_kind_owner"`
+	Balance   int       `gorm:"not null;default:0"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// LedgerEntry moves Amount from one account to another. Every movement of
+// money is a single entry, so debits and credits always balance.
+type LedgerEntry struct {
+	ID            int64  `gorm:"primaryKey;autoIncrement"`
+	FromAccountID int64  `gorm:"not null;index"`
+	ToAccountID   int64  `gorm:"not null;index"`
+	Amount        int    `gorm:"not null"`
+	Kind          string `gorm:"not null;index"`
+	Memo          string
+	CreatedAt     time.Time `gorm:"autoCreateTime;index"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Membership statuses
+const (
+	MemberActive = "member"
+	MemberLeft   = "left"
+)
+
+// Membership records that a user has been seen in a chat, and whether they
+// are still in it.
+type Membership struct {
+	ChatID    int64     `gorm:"primaryKey"`
+	UserID    int64     `gorm:"primaryKey;index"`
+	Status    string    `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
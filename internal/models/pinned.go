@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// PinnedBoard is a leaderboard message pinned in a chat that the bot keeps
+// up to date. There is at most one per chat.
+type PinnedBoard struct {
+	ChatID    int64     `gorm:"primaryKey"`
+	MessageID int       `gorm:"not null"`
+	Board     string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Season is one competitive period. EndsAt is zero when the season only ends
+// by admin command, EndedAt is zero while the season is running.
+type Season struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	Number    int       `gorm:"not null;uniqueIndex"`
+	StartedAt time.Time `gorm:"not null"`
+	EndsAt    time.Time
+	EndedAt   time.Time
+}
+
+// SeasonStanding is a user's final position in an ended season.
+type SeasonStanding struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement"`
+	SeasonID   int64  `gorm:"not null;index"`
+	UserID     int64  `gorm:"not null;index"`
+	Username   string `gorm:"not null"`
+	Rank       int    `gorm:"not null"`
+	Credit     int    `gorm:"not null"`
+	Money      int    `gorm:"not null"`
+	AliveScore int    `gorm:"not null"`
+}
+
+// Badge is awarded to the top finishers of a season.
+type Badge struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UserID    int64     `gorm:"not null;index"`
+	SeasonID  int64     `gorm:"not null"`
+	Rank      int       `gorm:"not null"`
+	Emoji     string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
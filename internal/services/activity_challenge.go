@@ -0,0 +1,157 @@
+package services
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Challenge types for activity checks
+const (
+	ChallengeButton     = "button"
+	ChallengeArithmetic = "arithmetic"
+	ChallengeEmoji      = "emoji"
+	ChallengeWord       = "word"
+)
+
+// AliveResult is the outcome of an answer to an activity check.
+type AliveResult int
+
+const (
+	// AliveIgnored means there was no check to answer
+	AliveIgnored AliveResult = iota
+	// AliveWrong means the answer was wrong and the check is still open
+	AliveWrong
+	// AliveExhausted means the user ran out of attempts for this check
+	AliveExhausted
+	// AliveAccepted means the check was answered correctly
+	AliveAccepted
+)
+
+var (
+	challengeEmojis = []string{"🍎", "🍌", "🍇", "🍉", "🍒", "🥕", "🌽", "🍋", "🥝", "🍍"}
+	challengeWords  = []string{"سلام", "زنده", "چای", "کتاب", "ماه", "باران", "درخت", "دریا"}
+)
+
+// challenge is one activity check question. Answers are compared ignoring
+// case and surrounding spaces.
+type challenge struct {
+	kind     string
+	question string
+	// answer is the typed word, or the token of the right option
+	answer string
+	// options are offered as buttons, empty for typed answers
+	options []string
+	// tokens identify the options in the callback data, so the data does
+	// not give the right option away
+	tokens []string
+}
+
+// newChallenge returns a random challenge of a type picked from kinds,
+// falling back to the plain button.
+func newChallenge(kinds []string) challenge {
+	kind := ChallengeButton
+	if len(kinds) > 0 {
+		kind = kinds[rand.IntN(len(kinds))]
+	}
+
+	switch kind {
+	case ChallengeArithmetic:
+		a, b := rand.IntN(19)+2, rand.IntN(19)+2
+		answer := a + b
+		options := []string{strconv.Itoa(answer)}
+		for _, offset := range rand.Perm(10)[:3] {
+			// Offsets 1-5 above or below the answer, never the answer itself
+			delta := offset%5 + 1
+			if offset >= 5 {
+				delta = -delta
+			}
+			options = append(options, strconv.Itoa(answer+delta))
+		}
+		shuffle(options)
+		return withTokens(challenge{
+			kind:     kind,
+			question: fmt.Sprintf("هی! زنده‌ای هنوز؟ حاصل %d + %d چنده؟", a, b),
+			answer:   strconv.Itoa(answer),
+			options:  options,
+		})
+	case ChallengeEmoji:
+		options := make([]string, 0, 6)
+		for _, i := range rand.Perm(len(challengeEmojis))[:6] {
+			options = append(options, challengeEmojis[i])
+		}
+		answer := options[rand.IntN(len(options))]
+		return withTokens(challenge{
+			kind:     kind,
+			question: fmt.Sprintf("هی! زنده‌ای هنوز؟ روی %s بزن.", answer),
+			answer:   answer,
+			options:  options,
+		})
+	case ChallengeWord:
+		answer := challengeWords[rand.IntN(len(challengeWords))]
+		return challenge{
+			kind:     kind,
+			question: fmt.Sprintf("هی! زنده‌ای هنوز؟ این کلمه رو همین‌جا تایپ کن: %s", answer),
+			answer:   answer,
+		}
+	}
+	return challenge{
+		kind:     ChallengeButton,
+		question: "هی! زنده‌ای هنوز؟ لطفاً با دکمه زیر پاسخ بده.",
+		answer:   "",
+		options:  []string{"🟢 بله، اینجام!"},
+	}
+}
+
+// keyboard returns the buttons of the challenge for userID, or nil for typed
+// answers. The plain button keeps the callback data older pings used.
+func (c challenge) keyboard(userID int64) *tgbotapi.InlineKeyboardMarkup {
+	if len(c.options) == 0 {
+		return nil
+	}
+	if c.kind == ChallengeButton {
+		markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(c.options[0], fmt.Sprintf("alive_%d", userID)),
+		))
+		return &markup
+	}
+
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(c.options))
+	for i, option := range c.options {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(option, fmt.Sprintf("alive_%d_%s", userID, c.tokens[i])))
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(row)
+	return &markup
+}
+
+// withTokens gives every option of c a random token and makes the token of
+// the right option the answer.
+func withTokens(c challenge) challenge {
+	c.tokens = make([]string, len(c.options))
+	for i, option := range c.options {
+		c.tokens[i] = newToken()
+		if option == c.answer {
+			c.answer = c.tokens[i]
+		}
+	}
+	return c
+}
+
+func newToken() string {
+	b := make([]byte, 8)
+	crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func shuffle(values []string) {
+	rand.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+}
+
+func sameAnswer(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChallengeKeyboardHidesAnswer(t *testing.T) {
+	for _, kind := range []string{ChallengeArithmetic, ChallengeEmoji} {
+		t.Run(kind, func(t *testing.T) {
+			c := newChallenge([]string{kind})
+			keyboard := c.keyboard(1)
+			if keyboard == nil {
+				t.Fatal("no keyboard")
+			}
+
+			right := 0
+			for _, button := range keyboard.InlineKeyboard[0] {
+				token := strings.TrimPrefix(*button.CallbackData, "alive_1_")
+				if sameAnswer(token, button.Text) {
+					t.Errorf("callback data %q is the option %q", *button.CallbackData, button.Text)
+				}
+				if sameAnswer(token, c.answer) {
+					right++
+				}
+			}
+			if right != 1 {
+				t.Errorf("%d buttons carry the answer token, want 1", right)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"social-credit/internal/models"
+)
+
+// ResponseStats summarizes how quickly a user answers activity checks.
+type ResponseStats struct {
+	Count  int
+	Median time.Duration
+	P90    time.Duration
+}
+
+// speedBonus returns the extra alive score for answering latency after the
+// ping, falling linearly from the configured bonus to none at the response
+// timeout.
+func (s *ActivityService) speedBonus(latency time.Duration) int {
+	bonus := s.config.App.ActivityCheck.Rewards.SpeedBonus
+	timeout := time.Duration(s.config.App.ActivityCheck.ResponseTimeout) * time.Second
+	if bonus <= 0 || timeout <= 0 || latency >= timeout {
+		return 0
+	}
+	return int(float64(bonus) * float64(timeout-latency) / float64(timeout))
+}
+
+// ResponseStats returns the response times of the answered pings of userID.
+// Late answers from inactive users coming back are left out.
+func (s *ActivityService) ResponseStats(userID int64) (*ResponseStats, error) {
+	var latencies []int
+	err := s.db.Model(&models.ActivityCheck{}).
+		Where("user_id = ? AND response = ? AND passive = ? AND late = ? AND ping_time > ?", userID, true, false, false, time.Time{}).
+		Pluck("latency", &latencies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ResponseStats{Count: len(latencies)}
+	if len(latencies) == 0 {
+		return stats, nil
+	}
+	sort.Ints(latencies)
+	stats.Median = percentile(latencies, 50)
+	stats.P90 = percentile(latencies, 90)
+	return stats, nil
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies in
+// seconds.
+func percentile(sorted []int, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return time.Duration(sorted[max(rank, 1)-1]) * time.Second
+}
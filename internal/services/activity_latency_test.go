@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"social-credit/internal/models"
+)
+
+func TestPercentile(t *testing.T) {
+	tens := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name   string
+		sorted []int
+		p      int
+		want   int
+	}{
+		{name: "single value", sorted: []int{7}, p: 50, want: 7},
+		{name: "single value p90", sorted: []int{7}, p: 90, want: 7},
+		{name: "median of two", sorted: []int{3, 9}, p: 50, want: 3},
+		{name: "median of ten", sorted: tens, p: 50, want: 5},
+		{name: "p90 of ten", sorted: tens, p: 90, want: 9},
+		{name: "p91 rounds up", sorted: tens, p: 91, want: 10},
+		{name: "p0 is the minimum", sorted: tens, p: 0, want: 1},
+		{name: "p100 is the maximum", sorted: tens, p: 100, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != time.Duration(tt.want)*time.Second {
+				t.Errorf("percentile(%v, %d) = %v, want %ds", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseStatsSkipsLateAnswers(t *testing.T) {
+	db := newTestDB(t, &models.ActivityCheck{})
+	s := NewActivityService(nil, nil, db, nil)
+	pinged := time.Now().Add(-time.Hour).UTC()
+	checks := []models.ActivityCheck{
+		{UserID: 1, Username: "a", Response: true, PingTime: pinged, Latency: 10},
+		{UserID: 1, Username: "a", Response: true, PingTime: pinged, Latency: 20},
+		{UserID: 1, Username: "a", Response: true, PingTime: pinged, Latency: 30000, Late: true},
+		{UserID: 1, Username: "a", Response: true, Passive: true},
+		{UserID: 1, Username: "a"},
+	}
+	if err := db.Create(&checks).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := s.ResponseStats(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Count != 2 || stats.Median != 10*time.Second || stats.P90 != 20*time.Second {
+		t.Errorf("stats = %+v, want 2 answers with median 10s and p90 20s", stats)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"social-credit/internal/models"
+)
+
+// recentlySeen reports whether the user chatted within the passive window,
+// which makes pinging them unnecessary.
+func (s *ActivityService) recentlySeen(status *models.ActivityStatus, now time.Time) bool {
+	window := time.Duration(s.config.App.ActivityCheck.PassiveWindow) * time.Second
+	return window > 0 && now.Sub(status.LastSeen) <= window
+}
+
+// satisfyPassively counts chat activity as an answer to the current check,
+// or to the ping the user would otherwise get, and awards the passive score.
+func (s *ActivityService) satisfyPassively(status *models.ActivityStatus, now time.Time) {
+	score := s.config.App.ActivityCheck.PassiveScore
+	updates := map[string]any{
+		"last_check":    now,
+		"last_response": status.LastSeen,
+		"ping_at":       time.Time{},
+	}
+
+	if status.State == models.ActivityIdle {
+		err := s.db.Model(&models.ActivityStatus{}).
+			Where("user_id = ? AND state = ?", status.UserID, models.ActivityIdle).
+			Updates(updates).Error
+		if err != nil {
+			s.sendAlert(fmt.Sprintf("Error saving activity status for user %s: %s", status.Username, err.Error()))
+			return
+		}
+	} else {
+		updates["retry_count"] = 0
+		updates["challenge"] = ""
+		updates["challenge_answer"] = ""
+		updates["failed_attempts"] = 0
+		updates["next_check_time"] = time.Time{}
+		changed, err := s.transition(status, models.ActivityIdle, reasonSeen, updates)
+		if err != nil {
+			s.sendAlert(fmt.Sprintf("Error saving activity status for user %s: %s", status.Username, err.Error()))
+			return
+		}
+		if !changed {
+			return
+		}
+	}
+
+	check := &models.ActivityCheck{
+		UserID:    status.UserID,
+		Username:  status.Username,
+		CheckTime: now,
+		Response:  true,
+		Passive:   true,
+		Score:     score,
+	}
+	if err := s.db.Create(check).Error; err != nil {
+		s.sendAlert(fmt.Sprintf("Error saving activity check for user %s: %s", status.Username, err.Error()))
+		return
+	}
+
+	if score == 0 {
+		return
+	}
+	if err := s.creditService.AwardPoints(context.Background(), status.UserID, score, "فعال در چت"); err != nil {
+		s.sendAlert(fmt.Sprintf("Error awarding points to user %s: %s", status.Username, err.Error()))
+	}
+}
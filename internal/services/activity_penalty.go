@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+
+	"social-credit/internal/models"
+)
+
+// errAlreadyForgiven means another reactivation already refunded the penalty.
+var errAlreadyForgiven = errors.New("penalty already refunded")
+
+// groupChatID returns the configured group chat, or false if there is none.
+func (s *ActivityService) groupChatID() (int64, bool) {
+	chatID, err := strconv.ParseInt(s.config.App.ActivityCheck.GroupChat, 10, 64)
+	return chatID, err == nil && chatID != 0
+}
+
+// penalize applies the configured consequences to a user who just became
+// inactive and remembers what they lost, so it can be refunded if they come
+// back within the grace period.
+func (s *ActivityService) penalize(status *models.ActivityStatus) {
+	penalties := s.config.App.ActivityCheck.Penalties
+	chatID, hasGroup := s.groupChatID()
+
+	credit := 0
+	if penalties.Credit > 0 {
+		err := s.creditService.AddCredit(&models.CreditEvent{
+			UserID: status.UserID,
+			ChatID: chatID,
+			Kind:   models.CreditInactive,
+			Amount: -penalties.Credit,
+		})
+		if err != nil {
+			s.sendAlert(fmt.Sprintf("Error taking SocialCredit from user %s: %s", status.Username, err.Error()))
+		} else {
+			credit = penalties.Credit
+		}
+	}
+
+	// Fines are paid into a treasury, so they need the group
+	money := 0
+	if penalties.Money > 0 && hasGroup {
+		collected, err := s.creditService.FineInactive(status.UserID, chatID, penalties.Money)
+		if err != nil {
+			s.sendAlert(fmt.Sprintf("Error fining user %s: %s", status.Username, err.Error()))
+		}
+		money = collected
+	}
+
+	err := s.db.Model(&models.ActivityStatus{}).
+		Where("user_id = ?", status.UserID).
+		Updates(map[string]any{
+			"penalized_at":   utcNow(),
+			"penalty_credit": credit,
+			"penalty_money":  money,
+		}).Error
+	if err != nil {
+		log.Printf("Error saving activity penalty: %v", err)
+	}
+
+	if penalties.Kick && hasGroup {
+		s.kick(chatID, status)
+	}
+	if credit > 0 || money > 0 {
+		s.sendAlert(fmt.Sprintf("کاربر %s به خاطر غیبت %d سوشال کردیت و %d پول از دست داد.", status.Username, credit, money))
+	}
+}
+
+// kick removes the user from the group without banning them.
+func (s *ActivityService) kick(chatID int64, status *models.ActivityStatus) {
+	member := tgbotapi.ChatMemberConfig{ChatID: chatID, UserID: status.UserID}
+	if _, err := s.bot.Request(tgbotapi.BanChatMemberConfig{ChatMemberConfig: member}); err != nil {
+		s.sendAlert(fmt.Sprintf("Error removing user %s from the group: %s", status.Username, err.Error()))
+		return
+	}
+	if _, err := s.bot.Request(tgbotapi.UnbanChatMemberConfig{ChatMemberConfig: member, OnlyIfBanned: true}); err != nil {
+		log.Printf("Error unbanning kicked user %d: %v", status.UserID, err)
+	}
+	s.sendAlert(fmt.Sprintf("کاربر %s از گروه بیرون انداخته شد. 🚪", status.Username))
+}
+
+// forgive refunds the penalty of a user who came back from inactive within
+// the grace period. Later returns keep the penalty.
+func (s *ActivityService) forgive(status *models.ActivityStatus) {
+	if status.PenaltyCredit == 0 && status.PenaltyMoney == 0 {
+		return
+	}
+	grace := time.Duration(s.config.App.ActivityCheck.Penalties.GracePeriod) * time.Second
+	if time.Since(status.PenalizedAt) > grace {
+		return
+	}
+
+	chatID, _ := s.groupChatID()
+	refunded := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Clearing the penalty with the refund makes sure it is only
+		// refunded once, and kept if the refund fails
+		result := tx.Model(&models.ActivityStatus{}).
+			Where("user_id = ? AND penalized_at = ? AND (penalty_credit > 0 OR penalty_money > 0)", status.UserID, status.PenalizedAt).
+			Updates(map[string]any{"penalty_credit": 0, "penalty_money": 0})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyForgiven
+		}
+		var err error
+		refunded, err = s.creditService.RefundInactive(tx, status.UserID, chatID, status.PenaltyCredit, status.PenaltyMoney)
+		return err
+	})
+	if errors.Is(err, errAlreadyForgiven) {
+		return
+	}
+	if err != nil {
+		s.sendAlert(fmt.Sprintf("Error refunding the penalty of user %s: %s", status.Username, err.Error()))
+		return
+	}
+	s.creditService.changed(chatID)
+	if refunded < status.PenaltyMoney {
+		s.sendAlert(fmt.Sprintf("کاربر %s به موقع برگشت، ولی خزانه فقط %d از %d پول جریمه‌اش رو داشت که پس داده شد.", status.Username, refunded, status.PenaltyMoney))
+		return
+	}
+	s.sendAlert(fmt.Sprintf("کاربر %s به موقع برگشت و جریمه‌اش پس داده شد.", status.Username))
+}
+
+// InactiveUsers returns which of userIDs are inactive.
+func (s *ActivityService) InactiveUsers(userIDs []int64) (map[int64]bool, error) {
+	var inactive []int64
+	err := s.db.Model(&models.ActivityStatus{}).
+		Where("user_id IN ? AND state = ?", userIDs, models.ActivityInactive).
+		Pluck("user_id", &inactive).Error
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[int64]bool, len(inactive))
+	for _, userID := range inactive {
+		users[userID] = true
+	}
+	return users, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+func TestForgive(t *testing.T) {
+	tests := []struct {
+		name      string
+		treasury  int
+		wantMoney int
+	}{
+		{name: "refunded", treasury: 4, wantMoney: 4},
+		{name: "treasury holds more", treasury: 9, wantMoney: 4},
+		// A treasury that spent the fine, for example on a /grant, pays back
+		// what it has left and the credit is refunded regardless
+		{name: "drained treasury", treasury: 1, wantMoney: 1},
+		{name: "empty treasury", treasury: 0, wantMoney: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, ledger := newTestLedger(t, models.Credit{UserID: 1, Username: "a", Credit: 10})
+			if err := db.AutoMigrate(&models.CreditEvent{}, &models.ActivityStatus{}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.treasury > 0 {
+				if err := ledger.Move(MintAccount(), TreasuryAccount(-100), tt.treasury, models.EntryGrant, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := &config.Config{}
+			cfg.App.ActivityCheck.GroupChat = "-100"
+			cfg.App.ActivityCheck.Penalties.GracePeriod = 3600
+			s := NewActivityService(nil, cfg, db, NewCreditService(db, ledger, NewPolicy(cfg.App.Economy)))
+
+			status := &models.ActivityStatus{
+				UserID:        1,
+				Username:      "a",
+				State:         models.ActivityIdle,
+				PenalizedAt:   time.Now().UTC(),
+				PenaltyCredit: 3,
+				PenaltyMoney:  4,
+			}
+			if err := db.Create(status).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			s.forgive(status)
+			// A second reactivation refunds nothing
+			s.forgive(status)
+
+			var saved models.ActivityStatus
+			if err := db.First(&saved, "user_id = ?", 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			var user models.Credit
+			if err := db.First(&user, "user_id = ?", 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			if saved.PenaltyCredit != 0 || saved.PenaltyMoney != 0 {
+				t.Errorf("penalty = %d credit %d money after the refund, want it cleared", saved.PenaltyCredit, saved.PenaltyMoney)
+			}
+			if user.Credit != 13 || user.Money != tt.wantMoney {
+				t.Errorf("user has %d credit %d money, want 13 and %d", user.Credit, user.Money, tt.wantMoney)
+			}
+			if got := balance(t, ledger, TreasuryAccount(-100)); got != tt.treasury-tt.wantMoney {
+				t.Errorf("treasury balance = %d, want %d", got, tt.treasury-tt.wantMoney)
+			}
+			report, err := ledger.Audit()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !report.OK() {
+				t.Errorf("audit found discrepancies: %v", report.Discrepancies)
+			}
+		})
+	}
+}
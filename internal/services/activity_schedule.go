@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"social-credit/internal/models"
+)
+
+// utcNow returns the current time in UTC. Activity times are all written and
+// compared in UTC, since sqlite compares them as text and a deadline stored
+// with another offset would sort wrongly against them.
+func utcNow() time.Time {
+	return time.Now().UTC()
+}
+
+// location returns the time zone of status, falling back to the configured
+// default and then UTC.
+func (s *ActivityService) location(status *models.ActivityStatus) *time.Location {
+	for _, name := range []string{status.TimeZone, s.config.App.ActivityCheck.DefaultTimeZone} {
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// quietUntil returns the end of the user's quiet hours if t falls inside
+// them, or the zero time if it does not.
+func (s *ActivityService) quietUntil(status *models.ActivityStatus, t time.Time) time.Time {
+	start, end := status.QuietStart, status.QuietEnd
+	if start == end {
+		return time.Time{}
+	}
+
+	local := t.In(s.location(status))
+	minute := local.Hour()*60 + local.Minute()
+	endsAt := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, local.Location())
+
+	switch {
+	case start < end && minute >= start && minute < end:
+		return endsAt.UTC()
+	case start > end && minute < end:
+		return endsAt.UTC()
+	case start > end && minute >= start:
+		// The quiet hours wrap past midnight
+		return endsAt.AddDate(0, 0, 1).UTC()
+	}
+	return time.Time{}
+}
+
+// deadline moves t out of the user's quiet hours.
+func (s *ActivityService) deadline(status *models.ActivityStatus, t time.Time) time.Time {
+	if until := s.quietUntil(status, t); !until.IsZero() {
+		return until
+	}
+	return t
+}
+
+// processDeferredPings pings idle users whose ping was held back by their
+// quiet hours.
+func (s *ActivityService) processDeferredPings() {
+	var due []models.ActivityStatus
+	err := s.db.Where("state = ? AND ping_at > ? AND ping_at <= ?", models.ActivityIdle, time.Time{}, utcNow()).
+		Find(&due).Error
+	if err != nil {
+		log.Printf("Error getting deferred activity pings: %v", err)
+		return
+	}
+
+	for _, status := range due {
+		s.checkUserActivity(&models.User{ID: status.UserID, Username: status.Username})
+	}
+}
+
+// Status returns the activity status of userID.
+func (s *ActivityService) Status(userID int64, username string) (*models.ActivityStatus, error) {
+	return s.status(&models.User{ID: userID, Username: username})
+}
+
+// SetTimeZone sets the IANA time zone quiet hours of userID are read in.
+func (s *ActivityService) SetTimeZone(userID int64, username, name string) error {
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("unknown time zone %q: %w", name, err)
+	}
+	if _, err := s.status(&models.User{ID: userID, Username: username}); err != nil {
+		return err
+	}
+	return s.db.Model(&models.ActivityStatus{}).
+		Where("user_id = ?", userID).
+		Update("time_zone", name).Error
+}
+
+// SetQuietHours sets the local minutes after midnight between which userID
+// is not pinged. Equal start and end turn quiet hours off.
+func (s *ActivityService) SetQuietHours(userID int64, username string, start, end int) error {
+	if _, err := s.status(&models.User{ID: userID, Username: username}); err != nil {
+		return err
+	}
+	return s.db.Model(&models.ActivityStatus{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{"quiet_start": start, "quiet_end": end}).Error
+}
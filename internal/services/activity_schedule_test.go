@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+// withLocal runs the rest of the test with the host in loc.
+func withLocal(t *testing.T, loc *time.Location) {
+	t.Helper()
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+}
+
+func TestQuietUntilWrapsPastMidnight(t *testing.T) {
+	withLocal(t, time.FixedZone("UTC-5", -5*60*60))
+	s := newTestActivityService(t)
+	// 22:00 to 07:00 at UTC+3
+	status := &models.ActivityStatus{TimeZone: "Etc/GMT-3", QuietStart: 22 * 60, QuietEnd: 7 * 60}
+
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{name: "start of the quiet hours", t: day(10, 19), want: day(11, 4)},
+		{name: "before midnight", t: day(10, 20), want: day(11, 4)},
+		{name: "after midnight", t: day(10, 22), want: day(11, 4)},
+		{name: "early morning", t: day(11, 1), want: day(11, 4)},
+		{name: "end of the quiet hours", t: day(11, 4)},
+		{name: "afternoon", t: day(11, 12)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.quietUntil(status, tt.t)
+			if !got.Equal(tt.want) {
+				t.Errorf("quietUntil(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+			if !got.IsZero() && got.Location() != time.UTC {
+				t.Errorf("quietUntil(%v) is in %v, want UTC", tt.t, got.Location())
+			}
+		})
+	}
+}
+
+func TestDeferredDeadlineNotDueEarly(t *testing.T) {
+	// Local text sorts after UTC text here, so a local now would find a
+	// deadline up to three and a half hours early
+	withLocal(t, time.FixedZone("UTC+3:30", 7*30*60))
+	db := newTestDB(t, &models.ActivityStatus{}, &models.ActivityTransition{}, &models.ActivityCheck{})
+	s := NewActivityService(nil, &config.Config{}, db, nil)
+
+	// The quiet hours that deferred the deadline have since been turned off
+	status := &models.ActivityStatus{
+		UserID:        1,
+		Username:      "a",
+		State:         models.ActivityPending,
+		LastCheck:     time.Now().UTC(),
+		NextCheckTime: time.Now().Add(time.Hour).UTC(),
+	}
+	if err := db.Create(status).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s.processTimeouts()
+
+	var saved models.ActivityStatus
+	if err := db.First(&saved, "user_id = ?", 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.State != models.ActivityPending {
+		t.Errorf("state = %s, want the check still pending an hour before its deadline", saved.State)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"strconv"
 	"time"
 
@@ -20,7 +21,6 @@ type ActivityService struct {
 	scheduler     *gocron.Scheduler
 	db            *gorm.DB
 	creditService *CreditService
-	scheduledJobs map[int64]*gocron.Job
 }
 
 func NewActivityService(bot *tgbotapi.BotAPI, config *config.Config, db *gorm.DB, creditService *CreditService) *ActivityService {
@@ -30,15 +30,32 @@ func NewActivityService(bot *tgbotapi.BotAPI, config *config.Config, db *gorm.DB
 		scheduler:     gocron.NewScheduler(time.UTC),
 		db:            db,
 		creditService: creditService,
-		scheduledJobs: make(map[int64]*gocron.Job),
 	}
 }
 
+// Start schedules the activity check rounds and a single poller that
+// handles every check whose deadline has passed. Pending checks live in the
+// database, so they survive restarts.
 func (s *ActivityService) Start() error {
-	_, err := s.scheduler.Cron(s.config.App.ActivityCheck.Schedule).Do(s.checkAllUsersActivity)
+	// Older versions left the deadline of answered checks in place
+	err := s.db.Model(&models.ActivityStatus{}).
+		Where("next_check_time > ? AND last_response >= last_check", time.Time{}).
+		Update("next_check_time", time.Time{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to clear answered activity checks: %w", err)
+	}
+	if err := s.importActivityStates(); err != nil {
+		return fmt.Errorf("failed to import activity states: %w", err)
+	}
+
+	_, err = s.scheduler.Cron(s.config.App.ActivityCheck.Schedule).Do(s.checkAllUsersActivity)
 	if err != nil {
 		return fmt.Errorf("failed to schedule activity checks: %w", err)
 	}
+	_, err = s.scheduler.Every(s.config.App.ActivityCheck.PollInterval).Seconds().SingletonMode().Do(s.processTimeouts)
+	if err != nil {
+		return fmt.Errorf("failed to schedule activity timeouts: %w", err)
+	}
 	s.scheduler.StartAsync()
 	return nil
 }
@@ -48,6 +65,8 @@ func (s *ActivityService) Stop() {
 }
 
 func (s *ActivityService) checkAllUsersActivity() {
+	s.endSnoozes()
+
 	var users []models.Credit
 	if err := s.db.Find(&users).Error; err != nil {
 		s.sendAlert("Error getting users for activity check: " + err.Error())
@@ -59,127 +78,422 @@ func (s *ActivityService) checkAllUsersActivity() {
 	}
 }
 
+// status returns the activity status of user, creating an idle one if the
+// user has never been checked.
+func (s *ActivityService) status(user *models.User) (*models.ActivityStatus, error) {
+	status := models.ActivityStatus{
+		UserID:    user.ID,
+		Username:  user.Username,
+		State:     models.ActivityIdle,
+		LastCheck: utcNow(),
+	}
+	err := s.db.Where("user_id = ?", user.ID).FirstOrCreate(&status).Error
+	return &status, err
+}
+
+// checkUserActivity pings an idle user and moves them to pending. Users in
+// any other state are either already being checked or not checked at all.
 func (s *ActivityService) checkUserActivity(user *models.User) {
-	var status models.ActivityStatus
-	err := s.db.Where("user_id = ?", user.ID).First(&status).Error
-	if err == gorm.ErrRecordNotFound {
-		status = models.ActivityStatus{
-			UserID:    user.ID,
-			Username:  user.Username,
-			IsActive:  true,
-			LastCheck: time.Now(),
-		}
-	} else if err != nil {
+	status, err := s.status(user)
+	if err != nil {
 		s.sendAlert(fmt.Sprintf("Error getting activity status for user %s: %s", user.Username, err.Error()))
 		return
 	}
-
-	// Cancel any existing scheduled check for this user
-	if existingJob, exists := s.scheduledJobs[user.ID]; exists {
-		s.scheduler.RemoveByReference(existingJob)
-		delete(s.scheduledJobs, user.ID)
+	if status.State != models.ActivityIdle {
+		return
 	}
 
-	msg := tgbotapi.NewMessage(user.ID, "هی! زنده‌ای هنوز؟ لطفاً با دکمه زیر پاسخ بده.")
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🟢 بله، اینجام!", fmt.Sprintf("alive_%d", user.ID)),
-		),
-	)
-	msg.ReplyMarkup = keyboard
+	now := utcNow()
+	if s.recentlySeen(status, now) {
+		s.satisfyPassively(status, now)
+		return
+	}
+	if until := s.quietUntil(status, now); !until.IsZero() {
+		// Ask again once the quiet hours are over
+		err := s.db.Model(&models.ActivityStatus{}).
+			Where("user_id = ?", user.ID).
+			Update("ping_at", until).Error
+		if err != nil {
+			s.sendAlert(fmt.Sprintf("Error saving activity status for user %s: %s", user.Username, err.Error()))
+		}
+		return
+	}
 
-	sentMsg, err := s.bot.Send(msg)
+	messageID, challenge, err := s.ping(status)
 	if err != nil {
-		// who cares?
+		log.Printf("Error pinging user %d: %v", user.ID, err)
 		return
 	}
 
-	status.MessageID = sentMsg.MessageID
-	status.LastCheck = time.Now()
-	status.NextCheckTime = time.Now().Add(time.Duration(s.config.App.ActivityCheck.ResponseTimeout) * time.Second)
-
-	if err := s.db.Save(&status).Error; err != nil {
+	_, err = s.transition(status, models.ActivityPending, reasonPinged, map[string]any{
+		"username":         user.Username,
+		"message_id":       messageID,
+		"challenge":        challenge.kind,
+		"challenge_answer": challenge.answer,
+		"failed_attempts":  0,
+		"last_check":       now,
+		"retry_count":      0,
+		"ping_at":          time.Time{},
+		"next_check_time":  s.deadline(status, now.Add(time.Duration(s.config.App.ActivityCheck.ResponseTimeout)*time.Second)),
+	})
+	if err != nil {
 		s.sendAlert(fmt.Sprintf("Error saving activity status for user %s: %s", user.Username, err.Error()))
 	}
+}
 
-	job, _ := s.scheduler.Every(s.config.App.ActivityCheck.ResponseTimeout).Seconds().Do(func() {
-		s.checkResponseTimeout(&status)
-	})
-	s.scheduledJobs[user.ID] = job
+// ping asks the user to prove they are alive with a challenge of one of the
+// configured types, in private or, if the bot cannot message them there,
+// with a mention in the group chat. It returns the ID of the sent message and
+// the challenge.
+func (s *ActivityService) ping(status *models.ActivityStatus) (int, challenge, error) {
+	challenge := newChallenge(s.config.App.ActivityCheck.Challenges)
+	msg := tgbotapi.NewMessage(status.UserID, challenge.question)
+	if keyboard := challenge.keyboard(status.UserID); keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+
+	sentMsg, err := s.bot.Send(msg)
+	if err == nil {
+		s.setReachable(status, true)
+		return sentMsg.MessageID, challenge, nil
+	}
+	if !undeliverable(err) {
+		return 0, challenge, err
+	}
+	s.setReachable(status, false)
+	return s.pingInGroup(status)
 }
 
+// processTimeouts handles every pending check whose deadline has passed, and
+// sends pings that were held back by quiet hours.
+func (s *ActivityService) processTimeouts() {
+	s.endSnoozes()
+	s.processDeferredPings()
+
+	var due []models.ActivityStatus
+	err := s.db.Where("state IN ? AND next_check_time <= ?", []string{models.ActivityPending, models.ActivityWarned}, utcNow()).
+		Find(&due).Error
+	if err != nil {
+		log.Printf("Error getting expired activity checks: %v", err)
+		return
+	}
+
+	for i := range due {
+		s.checkResponseTimeout(&due[i])
+	}
+}
+
+// checkResponseTimeout warns a user who missed a deadline and pings them
+// again, or marks them inactive once every retry is used up.
 func (s *ActivityService) checkResponseTimeout(status *models.ActivityStatus) {
-	if time.Now().Before(status.NextCheckTime) {
+	// Users who chatted since they were pinged are evidently alive
+	if s.config.App.ActivityCheck.PassiveWindow > 0 && status.LastSeen.After(status.LastCheck) {
+		s.satisfyPassively(status, utcNow())
 		return
 	}
 
-	if !status.IsActive {
+	// The user's quiet hours may have changed since the deadline was set
+	if until := s.quietUntil(status, utcNow()); !until.IsZero() {
+		err := s.db.Model(&models.ActivityStatus{}).
+			Where("user_id = ? AND next_check_time = ?", status.UserID, status.NextCheckTime).
+			Update("next_check_time", until).Error
+		if err != nil {
+			log.Printf("Error deferring activity timeout: %v", err)
+		}
 		return
 	}
 
-	status.RetryCount++
-	if status.RetryCount >= s.config.App.ActivityCheck.MaxRetries {
-		status.IsActive = false
-		s.sendAlert(fmt.Sprintf("کاربر %s دیگه جواب نمیده! غیرفعال شد. 💀", status.Username))
-	} else {
-		s.sendWarning(fmt.Sprintf("کاربر %s هنوز جواب نداده! %d بار دیگه چک می‌کنیم.", status.Username, s.config.App.ActivityCheck.MaxRetries-status.RetryCount))
-		status.NextCheckTime = time.Now().Add(time.Duration(s.config.App.ActivityCheck.RetryInterval) * time.Second)
-		s.checkUserActivity(&models.User{ID: status.UserID, Username: status.Username})
+	retries := status.RetryCount + 1
+	retriesLeft := s.config.App.ActivityCheck.MaxRetries - retries
+
+	if retriesLeft <= 0 {
+		changed, err := s.transition(status, models.ActivityInactive, reasonTimeout, map[string]any{
+			"retry_count":     retries,
+			"next_check_time": time.Time{},
+			"streak":          0,
+		})
+		if err != nil {
+			s.sendAlert(fmt.Sprintf("Error saving activity status for user %s: %s", status.Username, err.Error()))
+		}
+		if changed {
+			s.recordMissedCheck(status)
+			s.sendAlert(fmt.Sprintf("کاربر %s دیگه جواب نمیده! غیرفعال شد. 💀", status.Username))
+			s.penalize(status)
+		}
+		return
 	}
 
-	if err := s.db.Save(&status).Error; err != nil {
+	changed, err := s.transition(status, models.ActivityWarned, reasonTimeout, map[string]any{
+		"retry_count":     retries,
+		"next_check_time": s.deadline(status, utcNow().Add(time.Duration(s.config.App.ActivityCheck.RetryInterval)*time.Second)),
+		"streak":          0,
+	})
+	if err != nil {
 		s.sendAlert(fmt.Sprintf("Error saving activity status for user %s: %s", status.Username, err.Error()))
+		return
+	}
+	if !changed {
+		return
+	}
+	s.recordMissedCheck(status)
+
+	s.sendWarning(fmt.Sprintf("کاربر %s هنوز جواب نداده! %d بار دیگه چک می‌کنیم.", status.Username, retriesLeft))
+	messageID, challenge, err := s.ping(status)
+	if err != nil {
+		log.Printf("Error pinging user %d: %v", status.UserID, err)
+		return
+	}
+	// Like transition, only a check still waiting on this retry is updated
+	result := s.db.Model(&models.ActivityStatus{}).
+		Where("user_id = ? AND state = ? AND retry_count = ?", status.UserID, models.ActivityWarned, retries).
+		Updates(map[string]any{
+			"message_id":       messageID,
+			"challenge":        challenge.kind,
+			"challenge_answer": challenge.answer,
+			"failed_attempts":  0,
+			"last_check":       utcNow(),
+		})
+	if result.Error != nil {
+		s.sendAlert(fmt.Sprintf("Error saving activity status for user %s: %s", status.Username, result.Error.Error()))
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("Activity check of user %d moved on while they were pinged again", status.UserID)
 	}
 }
 
-func (s *ActivityService) HandleAliveResponse(userID int64, username string) {
-	var status models.ActivityStatus
-	err := s.db.Where("user_id = ?", userID).First(&status).Error
+// recordMissedCheck saves a check that timed out, with the wrong answers
+// given to it.
+func (s *ActivityService) recordMissedCheck(status *models.ActivityStatus) {
+	check := &models.ActivityCheck{
+		UserID:         status.UserID,
+		Username:       status.Username,
+		CheckTime:      utcNow(),
+		Response:       false,
+		FailedAttempts: status.FailedAttempts,
+	}
+	if err := s.db.Create(check).Error; err != nil {
+		s.sendAlert(fmt.Sprintf("Error saving activity check for user %s: %s", status.Username, err.Error()))
+	}
+}
+
+// HandleAliveResponse checks answer against the challenge of the pending
+// check of userID and rewards a correct one. Inactive users who answer their
+// last check are reactivated.
+func (s *ActivityService) HandleAliveResponse(userID int64, username, answer string) AliveResult {
+	status, err := s.status(&models.User{ID: userID, Username: username})
 	if err != nil {
 		s.sendAlert(fmt.Sprintf("Error getting activity status for user %s: %s", username, err.Error()))
-		return
+		return AliveIgnored
 	}
 
-	if !status.IsActive {
-		return
+	switch status.State {
+	case models.ActivityPending, models.ActivityWarned, models.ActivityInactive:
+	default:
+		return AliveIgnored
 	}
 
-	// Cancel the scheduled check for this user
-	if job, exists := s.scheduledJobs[userID]; exists {
-		s.scheduler.RemoveByReference(job)
-		delete(s.scheduledJobs, userID)
+	maxAttempts := s.config.App.ActivityCheck.MaxAttempts
+	if status.FailedAttempts >= maxAttempts {
+		return AliveExhausted
+	}
+	if !sameAnswer(answer, status.ChallengeAnswer) {
+		err := s.db.Model(&models.ActivityStatus{}).
+			Where("user_id = ? AND failed_attempts = ?", userID, status.FailedAttempts).
+			UpdateColumn("failed_attempts", gorm.Expr("failed_attempts + 1")).Error
+		if err != nil {
+			log.Printf("Error counting failed activity check attempt: %v", err)
+		}
+		if status.FailedAttempts+1 >= maxAttempts {
+			return AliveExhausted
+		}
+		return AliveWrong
 	}
 
-	status.LastResponse = time.Now()
-	status.RetryCount = 0
-	status.IsActive = true
+	// Late answers keep the streak that the missed deadline reset at zero
+	streak := status.Streak
+	if status.State == models.ActivityPending {
+		streak++
+	}
+	now := utcNow()
+	latency := now.Sub(status.LastCheck)
+	bonus := s.streakBonus(streak)
+	score := s.config.App.ActivityCheck.Rewards.AliveScore + bonus + s.speedBonus(latency)
 
-	if err := s.db.Save(&status).Error; err != nil {
+	changed, err := s.transition(status, models.ActivityIdle, reasonResponded, map[string]any{
+		"last_response":    now,
+		"retry_count":      0,
+		"challenge":        "",
+		"challenge_answer": "",
+		"failed_attempts":  0,
+		"next_check_time":  time.Time{},
+		"streak":           streak,
+		"best_streak":      max(streak, status.BestStreak),
+	})
+	if err != nil {
 		s.sendAlert(fmt.Sprintf("Error saving activity status for user %s: %s", username, err.Error()))
-		return
+		return AliveIgnored
+	}
+	// Only the first answer to a check counts
+	if !changed {
+		return AliveIgnored
 	}
 
 	// Save activity check record
 	check := &models.ActivityCheck{
-		UserID:    userID,
-		Username:  username,
-		CheckTime: time.Now(),
-		Response:  true,
-		Score:     1,
+		UserID:         userID,
+		Username:       username,
+		CheckTime:      now,
+		Response:       true,
+		Score:          score,
+		PingTime:       status.LastCheck,
+		Latency:        int(latency.Seconds()),
+		Late:           status.State == models.ActivityInactive,
+		FailedAttempts: status.FailedAttempts,
 	}
 	if err := s.db.Create(check).Error; err != nil {
 		s.sendAlert(fmt.Sprintf("Error saving activity check for user %s: %s", username, err.Error()))
-		return
+		return AliveAccepted
 	}
 
 	// Award points for being alive
-	err = s.creditService.AwardPoints(context.Background(), userID, s.config.App.ActivityCheck.Rewards.AliveScore, "زنده موندن")
+	err = s.creditService.AwardPoints(context.Background(), userID, score, "زنده موندن")
 	if err != nil {
 		s.sendAlert(fmt.Sprintf("Error awarding points to user %s: %s", username, err.Error()))
+		return AliveAccepted
+	}
+
+	if status.State == models.ActivityInactive {
+		s.sendAlert(fmt.Sprintf("کاربر %s برگشت و دوباره فعال شد! 🎉 %d امتیاز دریافت کرد.", username, score))
+		s.forgive(status)
+		return AliveAccepted
+	}
+	if bonus > 0 {
+		s.sendAlert(fmt.Sprintf("کاربر %s %d بار پشت سر هم به موقع جواب داد! 🔥 %d امتیاز دریافت کرد.", username, streak, score))
+		return AliveAccepted
+	}
+	s.sendAlert(fmt.Sprintf("کاربر %s زنده است! 🎉 %d امتیاز دریافت کرد.", username, score))
+	return AliveAccepted
+}
+
+// HandleTypedAnswer treats text sent to the bot in private as an answer if
+// the pending check of userID asks for a typed word.
+func (s *ActivityService) HandleTypedAnswer(userID int64, username, text string) AliveResult {
+	var count int64
+	err := s.db.Model(&models.ActivityStatus{}).
+		Where("user_id = ? AND challenge = ?", userID, ChallengeWord).
+		Count(&count).Error
+	if err != nil || count == 0 {
+		return AliveIgnored
+	}
+	return s.HandleAliveResponse(userID, username, text)
+}
+
+// HandleUserMessage records that userID was seen and reactivates them if
+// they were inactive. It is called for every message the bot sees, so it
+// only writes once a minute per user and only reads the status of users it
+// wrote for. Users without a status get one at their first check.
+func (s *ActivityService) HandleUserMessage(userID int64, username string) {
+	// A minute is precise enough, but inactive users always get through
+	now := utcNow()
+	result := s.db.Model(&models.ActivityStatus{}).
+		Where("user_id = ? AND (last_seen < ? OR state = ?)", userID, now.Add(-time.Minute), models.ActivityInactive).
+		UpdateColumn("last_seen", now)
+	if result.Error != nil {
+		log.Printf("Error saving last seen time: %v", result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
 		return
 	}
 
-	s.sendAlert(fmt.Sprintf("کاربر %s زنده است! 🎉 %d امتیاز دریافت کرد.", username, s.config.App.ActivityCheck.Rewards.AliveScore))
+	var status models.ActivityStatus
+	if err := s.db.Where("user_id = ?", userID).First(&status).Error; err != nil {
+		log.Printf("Error getting activity status: %v", err)
+		return
+	}
+	if status.State != models.ActivityInactive {
+		return
+	}
+	changed, err := s.transition(&status, models.ActivityIdle, reasonSpoke, map[string]any{"retry_count": 0})
+	if err != nil {
+		log.Printf("Error reactivating user %d: %v", userID, err)
+		return
+	}
+	if changed {
+		s.sendAlert(fmt.Sprintf("کاربر %s دوباره پیام داد و فعال شد! 👋", username))
+		s.forgive(&status)
+	}
+}
+
+// Snooze pauses activity checks for userID until the given time, dropping
+// any pending check. It returns false if the user opted out of checks.
+func (s *ActivityService) Snooze(userID int64, username string, until time.Time) (bool, error) {
+	status, err := s.status(&models.User{ID: userID, Username: username})
+	if err != nil {
+		return false, err
+	}
+	return s.transition(status, models.ActivitySnoozed, reasonSnoozed, map[string]any{
+		"snoozed_until":   until.UTC(),
+		"ping_at":         time.Time{},
+		"retry_count":     0,
+		"next_check_time": time.Time{},
+	})
+}
+
+// OptOut stops activity checks for userID until they opt back in. It
+// returns false if the user already opted out.
+func (s *ActivityService) OptOut(userID int64, username string) (bool, error) {
+	status, err := s.status(&models.User{ID: userID, Username: username})
+	if err != nil {
+		return false, err
+	}
+	return s.transition(status, models.ActivityOptedOut, reasonOptedOut, map[string]any{
+		"snoozed_until":   time.Time{},
+		"ping_at":         time.Time{},
+		"retry_count":     0,
+		"next_check_time": time.Time{},
+	})
+}
+
+// OptIn resumes activity checks for a user who opted out or snoozed them. It
+// returns false if checks were not paused.
+func (s *ActivityService) OptIn(userID int64, username string) (bool, error) {
+	status, err := s.status(&models.User{ID: userID, Username: username})
+	if err != nil {
+		return false, err
+	}
+	if status.State != models.ActivityOptedOut && status.State != models.ActivitySnoozed {
+		return false, nil
+	}
+	return s.transition(status, models.ActivityIdle, reasonOptedIn, map[string]any{"snoozed_until": time.Time{}})
+}
+
+// Paused returns every user who snoozed or opted out of activity checks.
+func (s *ActivityService) Paused() ([]models.ActivityStatus, error) {
+	var statuses []models.ActivityStatus
+	err := s.db.Where("state IN ?", []string{models.ActivitySnoozed, models.ActivityOptedOut}).
+		Order("state, snoozed_until, username").
+		Find(&statuses).Error
+	return statuses, err
+}
+
+// endSnoozes makes users whose snooze is over idle again.
+func (s *ActivityService) endSnoozes() {
+	var expired []models.ActivityStatus
+	err := s.db.Where("state = ? AND snoozed_until <= ?", models.ActivitySnoozed, utcNow()).
+		Find(&expired).Error
+	if err != nil {
+		log.Printf("Error getting expired snoozes: %v", err)
+		return
+	}
+
+	for i := range expired {
+		_, err := s.transition(&expired[i], models.ActivityIdle, reasonSnoozeEnded, map[string]any{"snoozed_until": time.Time{}})
+		if err != nil {
+			log.Printf("Error ending snooze: %v", err)
+		}
+	}
 }
 
 func (s *ActivityService) sendAlert(message string) {
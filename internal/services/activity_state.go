@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"social-credit/internal/models"
+)
+
+// activityTransitions lists the states each activity check state may move
+// to. Every state change goes through transition, which enforces it.
+var activityTransitions = map[string][]string{
+	models.ActivityIdle:     {models.ActivityPending, models.ActivitySnoozed, models.ActivityOptedOut},
+	models.ActivityPending:  {models.ActivityIdle, models.ActivityWarned, models.ActivityInactive, models.ActivitySnoozed, models.ActivityOptedOut},
+	models.ActivityWarned:   {models.ActivityIdle, models.ActivityWarned, models.ActivityInactive, models.ActivitySnoozed, models.ActivityOptedOut},
+	models.ActivityInactive: {models.ActivityIdle, models.ActivitySnoozed, models.ActivityOptedOut},
+	models.ActivitySnoozed:  {models.ActivityIdle, models.ActivitySnoozed, models.ActivityOptedOut},
+	models.ActivityOptedOut: {models.ActivityIdle},
+}
+
+// Transition reasons
+const (
+	reasonPinged      = "pinged"
+	reasonTimeout     = "timeout"
+	reasonResponded   = "responded"
+	reasonSpoke       = "spoke"
+	reasonSeen        = "seen"
+	reasonSnoozed     = "snoozed"
+	reasonSnoozeEnded = "snooze_ended"
+	reasonOptedOut    = "opted_out"
+	reasonOptedIn     = "opted_in"
+	reasonImported    = "imported"
+)
+
+// transition moves status to state to, saving updates alongside, and records
+// the change. It does nothing and returns false when the move is not allowed
+// or status changed since it was read.
+func (s *ActivityService) transition(status *models.ActivityStatus, to, reason string, updates map[string]any) (bool, error) {
+	if !slices.Contains(activityTransitions[status.State], to) {
+		return false, nil
+	}
+
+	changes := map[string]any{"state": to}
+	for column, value := range updates {
+		changes[column] = value
+	}
+
+	changed := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ActivityStatus{}).
+			Where("user_id = ? AND state = ? AND next_check_time = ?", status.UserID, status.State, status.NextCheckTime).
+			Updates(changes)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		changed = true
+		return tx.Create(&models.ActivityTransition{
+			UserID:    status.UserID,
+			FromState: status.State,
+			ToState:   to,
+			Reason:    reason,
+		}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to move user %d from %s to %s: %w", status.UserID, status.State, to, err)
+	}
+	return changed, nil
+}
+
+// importActivityStates derives the state of statuses saved before states
+// existed from their old is_active flag and pending deadline, then drops
+// the flag. It is the only place this happens; migration 012 leaves the
+// flag for it.
+func (s *ActivityService) importActivityStates() error {
+	migrator := s.db.Migrator()
+	if !migrator.HasColumn(&models.ActivityStatus{}, "is_active") {
+		return nil
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var statuses []models.ActivityStatus
+		if err := tx.Find(&statuses).Error; err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			var active bool
+			err := tx.Model(&models.ActivityStatus{}).
+				Select("is_active").
+				Where("user_id = ?", status.UserID).
+				Scan(&active).Error
+			if err != nil {
+				return err
+			}
+
+			state := models.ActivityIdle
+			switch {
+			case !active:
+				state = models.ActivityInactive
+			case status.NextCheckTime.After(time.Time{}) && status.RetryCount > 0:
+				state = models.ActivityWarned
+			case status.NextCheckTime.After(time.Time{}):
+				state = models.ActivityPending
+			}
+			if state == status.State {
+				continue
+			}
+
+			err = tx.Model(&models.ActivityStatus{}).Where("user_id = ?", status.UserID).Update("state", state).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Create(&models.ActivityTransition{
+				UserID:    status.UserID,
+				FromState: status.State,
+				ToState:   state,
+				Reason:    reasonImported,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Exec("ALTER TABLE activity_statuses DROP COLUMN is_active").Error
+	})
+}
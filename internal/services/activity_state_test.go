@@ -0,0 +1,245 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+func newTestActivityService(t *testing.T) *ActivityService {
+	t.Helper()
+	db := newTestDB(t, &models.ActivityStatus{}, &models.ActivityTransition{})
+	return NewActivityService(nil, &config.Config{}, db, nil)
+}
+
+func saveStatus(t *testing.T, s *ActivityService, state string) *models.ActivityStatus {
+	t.Helper()
+	status := &models.ActivityStatus{UserID: 1, Username: "a", State: state, LastCheck: time.Now()}
+	if state == models.ActivityPending || state == models.ActivityWarned {
+		status.NextCheckTime = time.Now().Add(time.Hour).UTC()
+	}
+	if err := s.db.Create(status).Error; err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{models.ActivityIdle, models.ActivityPending, true},
+		{models.ActivityIdle, models.ActivitySnoozed, true},
+		{models.ActivityIdle, models.ActivityOptedOut, true},
+		{models.ActivityIdle, models.ActivityWarned, false},
+		{models.ActivityIdle, models.ActivityInactive, false},
+		{models.ActivityIdle, models.ActivityIdle, false},
+		{models.ActivityPending, models.ActivityIdle, true},
+		{models.ActivityPending, models.ActivityWarned, true},
+		{models.ActivityPending, models.ActivityInactive, true},
+		{models.ActivityPending, models.ActivityPending, false},
+		{models.ActivityWarned, models.ActivityWarned, true},
+		{models.ActivityWarned, models.ActivityInactive, true},
+		{models.ActivityWarned, models.ActivityPending, false},
+		{models.ActivityInactive, models.ActivityIdle, true},
+		{models.ActivityInactive, models.ActivityPending, false},
+		{models.ActivityInactive, models.ActivityWarned, false},
+		{models.ActivitySnoozed, models.ActivityIdle, true},
+		{models.ActivitySnoozed, models.ActivityPending, false},
+		{models.ActivityOptedOut, models.ActivityIdle, true},
+		{models.ActivityOptedOut, models.ActivitySnoozed, false},
+		{models.ActivityOptedOut, models.ActivityPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			s := newTestActivityService(t)
+			status := saveStatus(t, s, tt.from)
+
+			changed, err := s.transition(status, tt.to, reasonPinged, map[string]any{"retry_count": 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.allowed {
+				t.Errorf("transition changed = %v, want %v", changed, tt.allowed)
+			}
+
+			var saved models.ActivityStatus
+			if err := s.db.First(&saved, "user_id = ?", 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			var transitions []models.ActivityTransition
+			if err := s.db.Find(&transitions).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.allowed {
+				if saved.State != tt.from || saved.RetryCount != 0 || len(transitions) != 0 {
+					t.Errorf("forbidden transition changed the status to %s, retries %d, with %d transitions", saved.State, saved.RetryCount, len(transitions))
+				}
+				return
+			}
+			if saved.State != tt.to || saved.RetryCount != 2 {
+				t.Errorf("status = %s with %d retries, want %s with 2", saved.State, saved.RetryCount, tt.to)
+			}
+			if len(transitions) != 1 {
+				t.Fatalf("%d transitions recorded, want 1", len(transitions))
+			}
+			got := transitions[0]
+			if got.FromState != tt.from || got.ToState != tt.to || got.Reason != reasonPinged {
+				t.Errorf("recorded %s -> %s (%s), want %s -> %s (%s)", got.FromState, got.ToState, got.Reason, tt.from, tt.to, reasonPinged)
+			}
+		})
+	}
+}
+
+func TestTransitionLostUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  string
+		first  string
+		second string
+	}{
+		// A timeout and an answer racing for the same pending check
+		{name: "answer after timeout", state: models.ActivityPending, first: models.ActivityWarned, second: models.ActivityIdle},
+		{name: "timeout after answer", state: models.ActivityPending, first: models.ActivityIdle, second: models.ActivityWarned},
+		// Two warnings for the same deadline
+		{name: "double warning", state: models.ActivityWarned, first: models.ActivityWarned, second: models.ActivityWarned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestActivityService(t)
+			status := saveStatus(t, s, tt.state)
+			stale := *status
+
+			// A retry moves the deadline, which the stale copy still has
+			changed, err := s.transition(status, tt.first, reasonTimeout, map[string]any{
+				"next_check_time": status.NextCheckTime.Add(time.Hour),
+			})
+			if err != nil || !changed {
+				t.Fatalf("first transition = %v, %v, want it to succeed", changed, err)
+			}
+
+			changed, err = s.transition(&stale, tt.second, reasonResponded, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed {
+				t.Error("transition from a stale status succeeded")
+			}
+
+			var saved models.ActivityStatus
+			if err := s.db.First(&saved, "user_id = ?", 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			if saved.State != tt.first {
+				t.Errorf("state = %s, want %s from the first transition", saved.State, tt.first)
+			}
+			var transitions int64
+			s.db.Model(&models.ActivityTransition{}).Count(&transitions)
+			if transitions != 1 {
+				t.Errorf("%d transitions recorded, want 1", transitions)
+			}
+		})
+	}
+}
+
+func TestHandleUserMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string
+		lastSeen  time.Duration
+		wantState string
+		wantSeen  bool
+	}{
+		{name: "seen a while ago", state: models.ActivityIdle, lastSeen: time.Hour, wantState: models.ActivityIdle, wantSeen: true},
+		{name: "seen just now", state: models.ActivityIdle, lastSeen: 10 * time.Second, wantState: models.ActivityIdle},
+		{name: "inactive seen just now", state: models.ActivityInactive, lastSeen: 10 * time.Second, wantState: models.ActivityIdle, wantSeen: true},
+		{name: "pending", state: models.ActivityPending, lastSeen: time.Hour, wantState: models.ActivityPending, wantSeen: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestActivityService(t)
+			status := saveStatus(t, s, tt.state)
+			lastSeen := time.Now().Add(-tt.lastSeen).UTC()
+			if err := s.db.Model(status).UpdateColumn("last_seen", lastSeen).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			s.HandleUserMessage(1, "a")
+
+			var saved models.ActivityStatus
+			if err := s.db.First(&saved, "user_id = ?", 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			if saved.State != tt.wantState {
+				t.Errorf("state = %s, want %s", saved.State, tt.wantState)
+			}
+			if seen := saved.LastSeen.After(lastSeen); seen != tt.wantSeen {
+				t.Errorf("last seen updated = %v, want %v", seen, tt.wantSeen)
+			}
+		})
+	}
+
+	// Users without a status are left to their first check
+	s := newTestActivityService(t)
+	s.HandleUserMessage(2, "b")
+	var count int64
+	s.db.Model(&models.ActivityStatus{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d statuses created by a message, want none", count)
+	}
+}
+
+func TestImportActivityStates(t *testing.T) {
+	s := newTestActivityService(t)
+	if err := s.db.Exec("ALTER TABLE activity_statuses ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT true").Error; err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Hour).UTC()
+	rows := []struct {
+		userID   int64
+		active   bool
+		deadline time.Time
+		retries  int
+		want     string
+	}{
+		{userID: 1, active: true, want: models.ActivityIdle},
+		{userID: 2, active: false, want: models.ActivityInactive},
+		{userID: 3, active: true, deadline: deadline, want: models.ActivityPending},
+		{userID: 4, active: true, deadline: deadline, retries: 1, want: models.ActivityWarned},
+	}
+	for _, row := range rows {
+		err := s.db.Exec("INSERT INTO activity_statuses (user_id, username, state, last_check, next_check_time, retry_count, is_active) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			row.userID, "a", models.ActivityIdle, time.Now().UTC(), row.deadline, row.retries, row.active).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.importActivityStates(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, row := range rows {
+		var saved models.ActivityStatus
+		if err := s.db.First(&saved, "user_id = ?", row.userID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if saved.State != row.want {
+			t.Errorf("user %d state = %s, want %s", row.userID, saved.State, row.want)
+		}
+	}
+	if s.db.Migrator().HasColumn(&models.ActivityStatus{}, "is_active") {
+		t.Error("is_active was not dropped")
+	}
+	// Starting again finds nothing to import
+	if err := s.importActivityStates(); err != nil {
+		t.Fatal(err)
+	}
+}
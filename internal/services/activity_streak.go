@@ -0,0 +1,22 @@
+package services
+
+import "social-credit/internal/models"
+
+// streakBonus returns the extra alive score for reaching streak on-time
+// responses in a row, which is only paid on the response that reaches a
+// configured milestone.
+func (s *ActivityService) streakBonus(streak int) int {
+	for _, milestone := range s.config.App.ActivityCheck.Rewards.StreakBonuses {
+		if milestone.Streak == streak {
+			return milestone.Bonus
+		}
+	}
+	return 0
+}
+
+// Streak returns the current and best streak of on-time responses of userID.
+func (s *ActivityService) Streak(userID int64) (current, best int, err error) {
+	var status models.ActivityStatus
+	err = s.db.Where("user_id = ?", userID).Limit(1).Find(&status).Error
+	return status.Streak, status.BestStreak, err
+}
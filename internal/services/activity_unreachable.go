@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"social-credit/internal/models"
+)
+
+// undeliverable reports whether err means the bot can never message the
+// user in private: they blocked the bot or never started it.
+func undeliverable(err error) bool {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == 403 || strings.Contains(strings.ToLower(apiErr.Message), "chat not found")
+}
+
+// setReachable saves whether the user can be messaged in private and reports
+// users who just became unreachable to the alerts channel.
+func (s *ActivityService) setReachable(status *models.ActivityStatus, reachable bool) {
+	if status.Unreachable == !reachable {
+		return
+	}
+	status.Unreachable = !reachable
+	err := s.db.Model(&models.ActivityStatus{}).
+		Where("user_id = ?", status.UserID).
+		UpdateColumn("unreachable", status.Unreachable).Error
+	if err != nil {
+		log.Printf("Error saving reachability of user %d: %v", status.UserID, err)
+		return
+	}
+
+	if reachable {
+		s.sendAlert(fmt.Sprintf("ربات دوباره می‌تونه به %s پیام بده. 📬", status.Username))
+		return
+	}
+	if _, ok := s.groupChatID(); !ok {
+		s.sendAlert(fmt.Sprintf("ربات نمی‌تونه به %s پیام بده و گروهی هم برای پرسیدن تنظیم نشده! 📭", status.Username))
+		return
+	}
+	s.sendAlert(fmt.Sprintf("ربات نمی‌تونه به %s پیام بده، از این به بعد تو گروه ازش می‌پرسیم. 📭", status.Username))
+}
+
+// pingInGroup asks the user with a mention in the group chat. Typed word
+// challenges are never sent there, since only answers in private count.
+func (s *ActivityService) pingInGroup(status *models.ActivityStatus) (int, challenge, error) {
+	var kinds []string
+	for _, kind := range s.config.App.ActivityCheck.Challenges {
+		if kind != ChallengeWord {
+			kinds = append(kinds, kind)
+		}
+	}
+	challenge := newChallenge(kinds)
+
+	chatID, ok := s.groupChatID()
+	if !ok {
+		return 0, challenge, fmt.Errorf("user %d is unreachable and no group chat is configured", status.UserID)
+	}
+
+	name := status.Username
+	if name == "" {
+		name = fmt.Sprint(status.UserID)
+	}
+	mention := fmt.Sprintf(`<a href="tg://user?id=%d">%s</a>`, status.UserID, html.EscapeString(name))
+	msg := tgbotapi.NewMessage(chatID, mention+" "+html.EscapeString(challenge.question))
+	msg.ParseMode = tgbotapi.ModeHTML
+	if keyboard := challenge.keyboard(status.UserID); keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+
+	sentMsg, err := s.bot.Send(msg)
+	if err != nil {
+		return 0, challenge, err
+	}
+	return sentMsg.MessageID, challenge, nil
+}
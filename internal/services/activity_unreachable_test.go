@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestUndeliverable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "blocked", err: &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}, want: true},
+		{name: "never started", err: &tgbotapi.Error{Code: 403, Message: "Forbidden: bot can't initiate conversation with a user"}, want: true},
+		{name: "deactivated", err: &tgbotapi.Error{Code: 403, Message: "Forbidden: user is deactivated"}, want: true},
+		{name: "chat not found", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}, want: true},
+		{name: "wrapped 403", err: fmt.Errorf("pinging: %w", &tgbotapi.Error{Code: 403, Message: "Forbidden"}), want: true},
+		{name: "other bad request", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: message text is empty"}},
+		{name: "rate limited", err: &tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 5"}},
+		{name: "server error", err: &tgbotapi.Error{Code: 502, Message: "Bad Gateway"}},
+		{name: "network error", err: errors.New("dial tcp: connection refused")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := undeliverable(tt.err); got != tt.want {
+				t.Errorf("undeliverable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
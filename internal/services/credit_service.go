@@ -2,7 +2,7 @@ package services
 
 import (
 	"context"
-	"fmt"
+	"strings"
 
 	"social-credit/internal/models"
 
@@ -10,27 +10,82 @@ import (
 )
 
 type CreditService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	ledger   *LedgerService
+	policy   Policy
+	onChange func(chatID int64)
 }
 
-func NewCreditService(db *gorm.DB) *CreditService {
-	return &CreditService{db: db}
+func NewCreditService(db *gorm.DB, ledger *LedgerService, policy Policy) *CreditService {
+	return &CreditService{db: db, ledger: ledger, policy: policy}
+}
+
+// OnChange registers fn to be called after every committed balance change
+// with the chat it happened in, or 0 if it was not made in a chat.
+func (s *CreditService) OnChange(fn func(chatID int64)) {
+	s.onChange = fn
+}
+
+func (s *CreditService) changed(chatID int64) {
+	if s.onChange != nil {
+		s.onChange(chatID)
+	}
 }
 
 func (s *CreditService) InitializeUser(userID int, username string, initialBalance int) error {
-	user := models.Credit{UserID: userID, Username: username}
-	result := s.db.FirstOrCreate(&user, models.Credit{UserID: userID})
-	if result.RowsAffected > 0 {
-		return s.db.Model(&user).Update("money", initialBalance).Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user := models.Credit{UserID: userID, Username: username}
+		result := tx.FirstOrCreate(&user, models.Credit{UserID: userID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if initialBalance <= 0 {
+			return s.ledger.Open(tx, UserAccount(int64(userID)))
+		}
+		return s.ledger.Post(tx, MintAccount(), UserAccount(int64(userID)), initialBalance, models.EntryGrant, "initial balance")
+	})
+	if err != nil {
+		return err
 	}
+	s.changed(0)
 	return nil
 }
 
-func (s *CreditService) AddCredit(userID int, amount int) error {
-	return s.db.Model(&models.Credit{}).
-		Where("user_id = ?", userID).
-		UpdateColumn("credit", gorm.Expr("credit + ?", amount)).
+// Vote applies the configured SocialCredit change for kind to targetID and
+// returns the amount applied.
+func (s *CreditService) Vote(voterID, targetID, chatID int64, kind string) (int, error) {
+	amount := s.policy.VoteAmount(kind)
+	err := s.AddCredit(&models.CreditEvent{
+		UserID:  targetID,
+		ActorID: voterID,
+		ChatID:  chatID,
+		Kind:    kind,
+		Amount:  amount,
+	})
+	return amount, err
+}
+
+// AddCredit applies a SocialCredit change and records it in the history.
+func (s *CreditService) AddCredit(event *models.CreditEvent) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.addCredit(tx, event)
+	})
+	if err != nil {
+		return err
+	}
+	s.changed(event.ChatID)
+	return nil
+}
+
+func (s *CreditService) addCredit(tx *gorm.DB, event *models.CreditEvent) error {
+	err := tx.Model(&models.Credit{}).
+		Where("user_id = ?", event.UserID).
+		UpdateColumn("credit", gorm.Expr("credit + ?", event.Amount)).
 		Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(event).Error
 }
 
 func (s *CreditService) GetUserCredit(userID int) (*models.Credit, error) {
@@ -39,56 +94,128 @@ func (s *CreditService) GetUserCredit(userID int) (*models.Credit, error) {
 	return &credit, err
 }
 
-func (s *CreditService) GetTopCredits(limit int) ([]models.Credit, error) {
-	var credits []models.Credit
-	err := s.db.Order("credit DESC").Limit(limit).Find(&credits).Error
-	return credits, err
+// fraudFineMemo marks the ledger entries of fraud fines, which the simulator
+// recomputes from its policy.
+const fraudFineMemo = "self-vote fraud"
+
+type TransferReceipt struct {
+	Amount int
+	Tax    int
 }
 
-func (s *CreditService) GetTopMoney(limit int) ([]models.Credit, error) {
-	var credits []models.Credit
-	err := s.db.Order("money DESC").Limit(limit).Find(&credits).Error
-	return credits, err
+// TransferMoney sends the configured transfer amount from sender to receiver
+// and withholds the configured tax from what the receiver got for the
+// treasury of the chat the transfer happened in.
+func (s *CreditService) TransferMoney(senderID, receiverID int, chatID int64) (*TransferReceipt, error) {
+	amount := s.policy.TransferAmount()
+	receipt := &TransferReceipt{Amount: amount, Tax: s.policy.TransferTax(amount)}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		receiver := UserAccount(int64(receiverID))
+		if err := s.ledger.Post(tx, UserAccount(int64(senderID)), receiver, receipt.Amount, models.EntryTransfer, ""); err != nil {
+			return err
+		}
+		if receipt.Tax > 0 {
+			return s.ledger.Post(tx, receiver, TreasuryAccount(chatID), receipt.Tax, models.EntryTax, "transfer tax")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.changed(chatID)
+	return receipt, nil
 }
 
-func (s *CreditService) GetTopAliveScores(limit int) ([]models.Credit, error) {
-	var credits []models.Credit
-	err := s.db.Order("alive_score DESC").Limit(limit).Find(&credits).Error
-	return credits, err
+// FineFraud collects the configured fraud fine into the chat treasury, or as
+// much of it as the user can pay, and returns the amount collected.
+func (s *CreditService) FineFraud(userID, chatID int64) (int, error) {
+	return s.fine(userID, chatID, s.policy.FraudFine(), fraudFineMemo)
 }
 
-func (s *CreditService) TransferMoney(senderID, receiverID int) error {
-	tx := s.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
+// FineInactive collects amount from a user who stopped answering activity
+// checks into the chat treasury, or as much of it as the user can pay, and
+// returns the amount collected.
+func (s *CreditService) FineInactive(userID, chatID int64, amount int) (int, error) {
+	return s.fine(userID, chatID, amount, "inactivity")
+}
 
-	var sender models.Credit
-	if err := tx.First(&sender, "user_id = ?", senderID).Error; err != nil {
-		tx.Rollback()
-		return err
+// RefundInactive gives the credit and money taken from an inactive user back
+// inside tx, so the refund commits or rolls back with the caller's changes.
+// The money comes from the chat treasury, which may have spent it since, so
+// only as much as it holds is paid back. It returns the money refunded; the
+// caller reports the change after commit.
+func (s *CreditService) RefundInactive(tx *gorm.DB, userID, chatID int64, credit, money int) (int, error) {
+	if credit > 0 {
+		err := s.addCredit(tx, &models.CreditEvent{
+			UserID: userID,
+			ChatID: chatID,
+			Kind:   models.CreditReturned,
+			Amount: credit,
+		})
+		if err != nil {
+			return 0, err
+		}
 	}
-
-	if sender.Money <= 0 {
-		tx.Rollback()
-		return fmt.Errorf("insufficient balance")
+	if money <= 0 {
+		return 0, nil
 	}
 
-	if err := tx.Model(&models.Credit{}).
-		Where("user_id = ?", senderID).
-		UpdateColumn("money", gorm.Expr("money - ?", 1)).Error; err != nil {
-		tx.Rollback()
-		return err
+	treasury, err := s.ledger.account(tx, TreasuryAccount(chatID))
+	if err != nil {
+		return 0, err
 	}
+	refunded := min(money, treasury.Balance)
+	if refunded <= 0 {
+		return 0, nil
+	}
+	return refunded, s.ledger.Post(tx, TreasuryAccount(chatID), UserAccount(userID), refunded, models.EntryRefund, "inactivity refund")
+}
 
-	if err := tx.Model(&models.Credit{}).
-		Where("user_id = ?", receiverID).
-		UpdateColumn("money", gorm.Expr("money + ?", 1)).Error; err != nil {
-		tx.Rollback()
-		return err
+func (s *CreditService) fine(userID, chatID int64, amount int, memo string) (int, error) {
+	collected := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.Credit
+		if err := tx.First(&user, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		collected = min(amount, user.Money)
+		if collected <= 0 {
+			collected = 0
+			return nil
+		}
+		return s.ledger.Post(tx, UserAccount(userID), TreasuryAccount(chatID), collected, models.EntryFine, memo)
+	})
+	if err != nil {
+		return 0, err
 	}
+	if collected > 0 {
+		s.changed(chatID)
+	}
+	return collected, nil
+}
 
-	return tx.Commit().Error
+func (s *CreditService) GetUserByUsername(username string) (*models.Credit, error) {
+	var credit models.Credit
+	err := s.db.First(&credit, "username = ?", username).Error
+	return &credit, err
+}
+
+// SearchUsers returns up to limit users whose username starts with prefix,
+// ignoring case, best SocialCredit first.
+func (s *CreditService) SearchUsers(prefix string, limit int) ([]models.Credit, error) {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(strings.ToLower(prefix))
+	var credits []models.Credit
+	err := s.db.Where(`LOWER(username) LIKE ? ESCAPE '\'`, escaped+"%").
+		Order("credit DESC, user_id").
+		Limit(limit).
+		Find(&credits).Error
+	return credits, err
+}
+
+// Tier returns the name of the tier credit qualifies for.
+func (s *CreditService) Tier(credit int) string {
+	return s.policy.Tier(credit).Name
 }
 
 func (s *CreditService) UpdateUsername(userID int, newUsername string) error {
@@ -98,8 +225,13 @@ func (s *CreditService) UpdateUsername(userID int, newUsername string) error {
 }
 
 func (s *CreditService) AwardPoints(ctx context.Context, userID int64, points int, reason string) error {
-	return s.db.Model(&models.Credit{}).
+	err := s.db.Model(&models.Credit{}).
 		Where("user_id = ?", userID).
 		UpdateColumn("alive_score", gorm.Expr("alive_score + ?", points)).
 		Error
+	if err != nil {
+		return err
+	}
+	s.changed(0)
+	return nil
 }
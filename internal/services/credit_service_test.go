@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+func TestLiveTransferTax(t *testing.T) {
+	db, ledger := newTestLedger(t, models.Credit{UserID: 1, Username: "a"}, models.Credit{UserID: 2, Username: "b"})
+	if err := ledger.Move(MintAccount(), UserAccount(1), 5, models.EntryGrant, ""); err != nil {
+		t.Fatal(err)
+	}
+	policy := NewLivePolicy(config.EconomyConfig{Transfer: config.TransferConfig{Amount: 3, TaxPercent: 100}})
+	credit := NewCreditService(db, ledger, policy)
+
+	receipt, err := credit.TransferMoney(1, 2, -100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The live bot keeps sending 1 but collects the configured tax
+	if receipt.Amount != 1 || receipt.Tax != 1 {
+		t.Errorf("receipt = %+v, want amount 1 and tax 1", receipt)
+	}
+	if got := balance(t, ledger, TreasuryAccount(-100)); got != 1 {
+		t.Errorf("treasury balance = %d, want 1", got)
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"social-credit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	dailyCooldown = 24 * time.Hour
+	// A /daily claimed within this long after the previous one continues the streak
+	dailyStreakWindow = 48 * time.Hour
+)
+
+// CooldownError is returned when an earning command is used too early.
+type CooldownError struct {
+	Remaining time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("on cooldown for another %s", e.Remaining.Round(time.Minute))
+}
+
+type DailyReceipt struct {
+	Amount int
+	Streak int
+}
+
+type WorkReceipt struct {
+	Amount int
+	Tier   string
+}
+
+type EarningService struct {
+	db     *gorm.DB
+	ledger *LedgerService
+	policy Policy
+}
+
+func NewEarningService(db *gorm.DB, ledger *LedgerService, policy Policy) *EarningService {
+	return &EarningService{db: db, ledger: ledger, policy: policy}
+}
+
+// ClaimDaily pays the daily reward once per 24 hours. Consecutive claims grow
+// the streak, which raises the reward up to the configured maximum.
+func (s *EarningService) ClaimDaily(userID int64) (*DailyReceipt, error) {
+	receipt := &DailyReceipt{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		earning, err := s.earning(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if elapsed := now.Sub(earning.LastDaily); elapsed < dailyCooldown {
+			return &CooldownError{Remaining: dailyCooldown - elapsed}
+		}
+
+		receipt.Streak = 1
+		if now.Sub(earning.LastDaily) < dailyStreakWindow {
+			receipt.Streak = earning.DailyStreak + 1
+		}
+		receipt.Amount = s.policy.DailyReward(receipt.Streak)
+
+		// Guarded by the previous claim time so concurrent claims cannot both pay out
+		result := tx.Model(&models.Earning{}).
+			Where("user_id = ? AND last_daily = ?", userID, earning.LastDaily).
+			Updates(map[string]any{"last_daily": now, "daily_streak": receipt.Streak})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &CooldownError{Remaining: dailyCooldown}
+		}
+
+		if receipt.Amount <= 0 {
+			return nil
+		}
+		return s.ledger.Post(tx, MintAccount(), UserAccount(userID), receipt.Amount, models.EntryDaily, fmt.Sprintf("day %d streak", receipt.Streak))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// Work pays the work reward scaled by the user's SocialCredit tier, at most
+// once per configured cooldown.
+func (s *EarningService) Work(userID int64) (*WorkReceipt, error) {
+	receipt := &WorkReceipt{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		earning, err := s.earning(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		cooldown := s.policy.WorkCooldown()
+		if elapsed := now.Sub(earning.LastWork); elapsed < cooldown {
+			return &CooldownError{Remaining: cooldown - elapsed}
+		}
+
+		var credit models.Credit
+		if err := tx.First(&credit, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		tier := s.policy.Tier(credit.Credit)
+		receipt.Tier = tier.Name
+		receipt.Amount = s.policy.WorkReward(credit.Credit)
+
+		result := tx.Model(&models.Earning{}).
+			Where("user_id = ? AND last_work = ?", userID, earning.LastWork).
+			Update("last_work", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return &CooldownError{Remaining: cooldown}
+		}
+
+		if receipt.Amount <= 0 {
+			return nil
+		}
+		return s.ledger.Post(tx, MintAccount(), UserAccount(userID), receipt.Amount, models.EntryWork, tier.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+func (s *EarningService) earning(tx *gorm.DB, userID int64) (*models.Earning, error) {
+	earning := models.Earning{UserID: userID}
+	err := tx.FirstOrCreate(&earning, models.Earning{UserID: userID}).Error
+	return &earning, err
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+func newTestEarning(t *testing.T) (*EarningService, *LedgerService) {
+	t.Helper()
+	db, ledger := newTestLedger(t, models.Credit{UserID: 1, Username: "a", Credit: 25})
+	if err := db.AutoMigrate(&models.Earning{}); err != nil {
+		t.Fatal(err)
+	}
+	policy := NewPolicy(config.EconomyConfig{
+		Daily: config.DailyConfig{Amount: 10, StreakBonusPercent: 50, MaxStreak: 3},
+		Work:  config.WorkConfig{Amount: 2, Cooldown: 3600},
+		Tiers: []config.TierConfig{{Name: "Citizen", MinCredit: 0, Multiplier: 1}, {Name: "Model Citizen", MinCredit: 20, Multiplier: 1.5}},
+	})
+	return NewEarningService(db, ledger, policy), ledger
+}
+
+func TestClaimDaily(t *testing.T) {
+	tests := []struct {
+		name       string
+		lastDaily  time.Duration
+		streak     int
+		wantStreak int
+		wantAmount int
+		cooldown   bool
+	}{
+		{name: "first claim", wantStreak: 1, wantAmount: 10},
+		{name: "claimed already today", lastDaily: time.Hour, streak: 2, cooldown: true},
+		{name: "next day continues the streak", lastDaily: 25 * time.Hour, streak: 1, wantStreak: 2, wantAmount: 15},
+		{name: "missed day resets the streak", lastDaily: 50 * time.Hour, streak: 5, wantStreak: 1, wantAmount: 10},
+		{name: "bonus stops at max_streak", lastDaily: 25 * time.Hour, streak: 3, wantStreak: 4, wantAmount: 20},
+		{name: "streak keeps counting past max_streak", lastDaily: 25 * time.Hour, streak: 9, wantStreak: 10, wantAmount: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ledger := newTestEarning(t)
+			if tt.lastDaily > 0 {
+				earning := models.Earning{UserID: 1, LastDaily: time.Now().Add(-tt.lastDaily), DailyStreak: tt.streak}
+				if err := s.db.Create(&earning).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			receipt, err := s.ClaimDaily(1)
+			if tt.cooldown {
+				var cooldown *CooldownError
+				if !errors.As(err, &cooldown) {
+					t.Fatalf("ClaimDaily error = %v, want a cooldown", err)
+				}
+				if got := balance(t, ledger, UserAccount(1)); got != 0 {
+					t.Errorf("balance = %d after a rejected claim, want 0", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if receipt.Streak != tt.wantStreak || receipt.Amount != tt.wantAmount {
+				t.Errorf("receipt = %+v, want streak %d and amount %d", receipt, tt.wantStreak, tt.wantAmount)
+			}
+			if got := balance(t, ledger, UserAccount(1)); got != tt.wantAmount {
+				t.Errorf("balance = %d, want %d", got, tt.wantAmount)
+			}
+
+			// Claiming again straight away pays nothing
+			var cooldown *CooldownError
+			if _, err := s.ClaimDaily(1); !errors.As(err, &cooldown) {
+				t.Errorf("second ClaimDaily error = %v, want a cooldown", err)
+			}
+			if got := balance(t, ledger, UserAccount(1)); got != tt.wantAmount {
+				t.Errorf("balance = %d after a second claim, want %d", got, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestWork(t *testing.T) {
+	s, ledger := newTestEarning(t)
+
+	receipt, err := s.Work(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Amount != 3 || receipt.Tier != "Model Citizen" {
+		t.Errorf("receipt = %+v, want 3 as a Model Citizen", receipt)
+	}
+
+	var cooldown *CooldownError
+	if _, err := s.Work(1); !errors.As(err, &cooldown) {
+		t.Fatalf("second Work error = %v, want a cooldown", err)
+	}
+	if cooldown.Remaining <= 0 || cooldown.Remaining > time.Hour {
+		t.Errorf("remaining cooldown = %v, want up to an hour", cooldown.Remaining)
+	}
+
+	// Once the cooldown has passed work pays again
+	if err := s.db.Model(&models.Earning{}).Where("user_id = ?", 1).Update("last_work", time.Now().Add(-2*time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Work(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := balance(t, ledger, UserAccount(1)); got != 6 {
+		t.Errorf("balance = %d, want 6 after two shifts", got)
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"social-credit/internal/models"
+)
+
+const velocityWindow = 30 * 24 * time.Hour
+
+type EconomyStats struct {
+	Citizens      int
+	MoneySupply   int
+	MeanBalance   float64
+	MedianBalance float64
+	Gini          float64
+	TopOnePercent float64
+	Velocity      float64
+	Credits       []Bucket
+}
+
+type EconomyService struct {
+	db *gorm.DB
+}
+
+func NewEconomyService(db *gorm.DB) *EconomyService {
+	return &EconomyService{db: db}
+}
+
+func (s *EconomyService) Stats() (*EconomyStats, error) {
+	var credits []models.Credit
+	if err := s.db.Find(&credits).Error; err != nil {
+		return nil, err
+	}
+
+	balances := make([]int, 0, len(credits))
+	scores := make([]int, 0, len(credits))
+	for _, c := range credits {
+		balances = append(balances, c.Money)
+		scores = append(scores, c.Credit)
+	}
+
+	stats := &EconomyStats{
+		Citizens:      len(credits),
+		MeanBalance:   Mean(balances),
+		MedianBalance: Median(balances),
+		Gini:          Gini(balances),
+		TopOnePercent: TopShare(balances, 0.01),
+		Credits:       Histogram(scores, 8),
+	}
+
+	err := s.db.Model(&models.Account{}).
+		Where("kind <> ?", models.AccountMint).
+		Select("COALESCE(SUM(balance), 0)").
+		Scan(&stats.MoneySupply).Error
+	if err != nil {
+		return nil, err
+	}
+
+	velocity, err := s.velocity()
+	if err != nil {
+		return nil, err
+	}
+	stats.Velocity = velocity
+
+	return stats, nil
+}
+
+// velocity is the number of transfers per day over the last 30 days, or
+// since the first transfer if the economy is younger than that.
+func (s *EconomyService) velocity() (float64, error) {
+	since := time.Now().Add(-velocityWindow)
+
+	var first models.LedgerEntry
+	err := s.db.Where("kind = ?", models.EntryTransfer).Order("created_at").First(&first).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if first.CreatedAt.After(since) {
+		since = first.CreatedAt
+	}
+
+	var count int64
+	err = s.db.Model(&models.LedgerEntry{}).
+		Where("kind = ? AND created_at >= ?", models.EntryTransfer, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	days := max(time.Since(since).Hours()/24, 1)
+	return float64(count) / days, nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"social-credit/internal/models"
+)
+
+type SeriesPoint struct {
+	Time  time.Time
+	Value int
+}
+
+// Timeline metrics
+const (
+	MetricCredit = "credit"
+	MetricMoney  = "money"
+	MetricAlive  = "alive"
+)
+
+// TimelineEntry is one recorded change to a user's credit, money or alive
+// score. Actor is who caused it, empty when the bot did.
+type TimelineEntry struct {
+	Time   time.Time
+	Metric string
+	Amount int
+	Reason string
+	Memo   string
+	Actor  string
+}
+
+type HistoryService struct {
+	db     *gorm.DB
+	ledger *LedgerService
+}
+
+func NewHistoryService(db *gorm.DB, ledger *LedgerService) *HistoryService {
+	return &HistoryService{db: db, ledger: ledger}
+}
+
+// Timeline returns the most recent changes of every metric for userID,
+// newest first.
+func (s *HistoryService) Timeline(userID int64, limit int) ([]TimelineEntry, error) {
+	var entries []TimelineEntry
+
+	var events []struct {
+		models.CreditEvent
+		Actor string
+	}
+	err := s.db.Model(&models.CreditEvent{}).
+		Select("credit_events.*, actors.username AS actor").
+		Joins("LEFT JOIN credits AS actors ON actors.user_id = credit_events.actor_id AND credit_events.actor_id <> 0").
+		Where("credit_events.user_id = ?", userID).
+		Order("credit_events.id DESC").
+		Limit(limit).
+		Scan(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		entries = append(entries, TimelineEntry{
+			Time:   e.CreatedAt,
+			Metric: MetricCredit,
+			Amount: e.Amount,
+			Reason: e.Kind,
+			Actor:  e.Actor,
+		})
+	}
+
+	lines, err := s.ledger.Statement(UserAccount(userID), limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		actor := line.Counterparty.Kind
+		if line.Counterparty.Kind == models.AccountUser {
+			var counterparty models.Credit
+			if err := s.db.First(&counterparty, "user_id = ?", line.Counterparty.OwnerID).Error; err == nil {
+				actor = counterparty.Username
+			}
+		}
+		entries = append(entries, TimelineEntry{
+			Time:   line.Entry.CreatedAt,
+			Metric: MetricMoney,
+			Amount: line.Amount,
+			Reason: line.Entry.Kind,
+			Memo:   line.Entry.Memo,
+			Actor:  actor,
+		})
+	}
+
+	var checks []models.ActivityCheck
+	err = s.db.Where("user_id = ? AND response AND score <> 0", userID).
+		Order("check_time DESC").
+		Limit(limit).
+		Find(&checks).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range checks {
+		entries = append(entries, TimelineEntry{
+			Time:   c.CheckTime,
+			Metric: MetricAlive,
+			Amount: c.Score,
+			Reason: "activity_check",
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// DailyCredit returns the SocialCredit of userID at the end of each of the
+// last days days in UTC, oldest first. The last value is the current one.
+func (s *HistoryService) DailyCredit(userID int64, days int) ([]int, error) {
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	series, err := s.CreditSeries(userID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]int, days)
+	i := 0
+	for day := range values {
+		end := start.AddDate(0, 0, day+1)
+		for i+1 < len(series) && series[i+1].Time.Before(end) {
+			i++
+		}
+		values[day] = series[i].Value
+	}
+	return values, nil
+}
+
+// CreditSeries returns the SocialCredit of userID after every recorded change
+// since the given time, starting with the balance at that time and ending
+// with the current one. Changes that predate the history are folded into the
+// starting balance.
+func (s *HistoryService) CreditSeries(userID int64, since time.Time) ([]SeriesPoint, error) {
+	var credit models.Credit
+	if err := s.db.First(&credit, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	var events []models.CreditEvent
+	err := s.db.Where("user_id = ? AND created_at >= ?", userID, since).
+		Order("created_at, id").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	value := credit.Credit
+	for _, e := range events {
+		value -= e.Amount
+	}
+
+	points := make([]SeriesPoint, 0, len(events)+2)
+	points = append(points, SeriesPoint{Time: since, Value: value})
+	for _, e := range events {
+		value += e.Amount
+		points = append(points, SeriesPoint{Time: e.CreatedAt, Value: value})
+	}
+	points = append(points, SeriesPoint{Time: time.Now(), Value: value})
+	return points, nil
+}
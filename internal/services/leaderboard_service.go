@@ -0,0 +1,212 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"social-credit/internal/models"
+)
+
+// Leaderboard boards. The first three are named after the credits column they
+// rank.
+const (
+	BoardCredit        = "credit"
+	BoardMoney         = "money"
+	BoardAlive         = "alive_score"
+	BoardNegativeVotes = "negative_votes"
+)
+
+// Leaderboard windows. A windowed board ranks what users gained within the
+// window, computed from the recorded history, instead of current totals.
+const (
+	WindowAll   = "all"
+	WindowDay   = "day"
+	WindowWeek  = "week"
+	WindowMonth = "month"
+)
+
+var windowDurations = map[string]time.Duration{
+	WindowDay:   24 * time.Hour,
+	WindowWeek:  7 * 24 * time.Hour,
+	WindowMonth: 30 * 24 * time.Hour,
+}
+
+func IsWindow(window string) bool {
+	_, ok := windowDurations[window]
+	return ok || window == WindowAll
+}
+
+type LeaderboardQuery struct {
+	Board  string
+	Window string
+	// ChatID limits the board to current members of the chat when set
+	ChatID int64
+	// Ascending ranks the lowest values first
+	Ascending bool
+	// NegativeOnly leaves out users whose value is zero or more
+	NegativeOnly bool
+	Offset       int
+	Limit        int
+}
+
+type LeaderboardEntry struct {
+	Rank     int
+	UserID   int64
+	Username string
+	Value    int
+}
+
+type LeaderboardPage struct {
+	Entries []LeaderboardEntry
+	Total   int64
+}
+
+type LeaderboardService struct {
+	db *gorm.DB
+}
+
+func NewLeaderboardService(db *gorm.DB) *LeaderboardService {
+	return &LeaderboardService{db: db}
+}
+
+// Page returns one page of the board, best first unless the query is
+// ascending. Ties are broken by user ID so paging is stable.
+func (s *LeaderboardService) Page(q LeaderboardQuery) (*LeaderboardPage, error) {
+	rows, err := s.rows(q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &LeaderboardPage{}
+	if err := rows.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	rows, _ = s.rows(q)
+	err = rows.Order(q.order()).
+		Offset(q.Offset).
+		Limit(q.Limit).
+		Scan(&page.Entries).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Entries {
+		page.Entries[i].Rank = q.Offset + i + 1
+	}
+	return page, nil
+}
+
+// Rank returns the position of userID on the board, or nil if the user is not
+// on it.
+func (s *LeaderboardService) Rank(q LeaderboardQuery, userID int64) (*LeaderboardEntry, error) {
+	rows, err := s.rows(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LeaderboardEntry
+	if err := rows.Where("user_id = ?", userID).Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	entry := entries[0]
+
+	rows, _ = s.rows(q)
+	var ahead int64
+	better := "value > ?"
+	if q.Ascending {
+		better = "value < ?"
+	}
+	err = rows.Where(better+" OR (value = ? AND user_id < ?)", entry.Value, entry.Value, userID).
+		Count(&ahead).Error
+	if err != nil {
+		return nil, err
+	}
+	entry.Rank = int(ahead) + 1
+	return &entry, nil
+}
+
+func (q LeaderboardQuery) order() string {
+	if q.Ascending {
+		return "value, user_id"
+	}
+	return "value DESC, user_id"
+}
+
+// rows returns a query over (user_id, username, value) for the board.
+func (s *LeaderboardService) rows(q LeaderboardQuery) (*gorm.DB, error) {
+	base, err := s.base(q)
+	if err != nil {
+		return nil, err
+	}
+	rows := s.db.Table("(?) AS board", base)
+	if q.NegativeOnly {
+		rows = rows.Where("value < 0")
+	}
+	if q.ChatID != 0 {
+		members := s.db.Model(&models.Membership{}).
+			Select("user_id").
+			Where("chat_id = ? AND status = ?", q.ChatID, models.MemberActive)
+		rows = rows.Where("user_id IN (?)", members)
+	}
+	return rows, nil
+}
+
+// base selects the unranked board rows: current totals for the all-time
+// window, otherwise what was gained within the window.
+func (s *LeaderboardService) base(q LeaderboardQuery) (*gorm.DB, error) {
+	var since time.Time
+	if q.Window != "" && q.Window != WindowAll {
+		duration, ok := windowDurations[q.Window]
+		if !ok {
+			return nil, fmt.Errorf("unknown leaderboard window: %s", q.Window)
+		}
+		since = time.Now().Add(-duration)
+	}
+
+	if q.Board == BoardNegativeVotes {
+		return s.db.Model(&models.CreditEvent{}).
+			Select("credits.user_id, credits.username, COUNT(*) AS value").
+			Joins("JOIN credits ON credits.user_id = credit_events.actor_id").
+			Where("credit_events.created_at >= ? AND credit_events.kind = ?", since, models.CreditNegative).
+			Group("credits.user_id, credits.username"), nil
+	}
+
+	if since.IsZero() {
+		switch q.Board {
+		case BoardCredit, BoardMoney, BoardAlive:
+			return s.db.Model(&models.Credit{}).
+				Select("user_id, username, " + q.Board + " AS value"), nil
+		}
+		return nil, fmt.Errorf("unknown leaderboard: %s", q.Board)
+	}
+
+	switch q.Board {
+	case BoardCredit:
+		return s.db.Model(&models.CreditEvent{}).
+			Select("credits.user_id, credits.username, SUM(credit_events.amount) AS value").
+			Joins("JOIN credits ON credits.user_id = credit_events.user_id").
+			Where("credit_events.created_at >= ? AND credit_events.kind NOT IN ?", since, []string{models.CreditDecay, models.CreditSeason}).
+			Group("credits.user_id, credits.username"), nil
+	case BoardMoney:
+		return s.db.Raw(`SELECT credits.user_id, credits.username, SUM(flows.delta) AS value FROM (
+				SELECT to_account_id AS account_id, amount AS delta FROM ledger_entries WHERE created_at >= ?
+				UNION ALL
+				SELECT from_account_id AS account_id, -amount AS delta FROM ledger_entries WHERE created_at >= ?
+			) flows
+			JOIN accounts ON accounts.id = flows.account_id AND accounts.kind = ?
+			JOIN credits ON credits.user_id = accounts.owner_id
+			GROUP BY credits.user_id, credits.username`, since, since, models.AccountUser), nil
+	case BoardAlive:
+		return s.db.Model(&models.ActivityCheck{}).
+			Select("credits.user_id, credits.username, SUM(activity_checks.score) AS value").
+			Joins("JOIN credits ON credits.user_id = activity_checks.user_id").
+			Where("activity_checks.check_time >= ? AND activity_checks.response", since.UTC()).
+			Group("credits.user_id, credits.username"), nil
+	}
+	return nil, fmt.Errorf("unknown leaderboard: %s", q.Board)
+}
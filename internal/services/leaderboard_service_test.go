@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+func TestWindowedAliveBoard(t *testing.T) {
+	db := newTestDB(t, &models.Credit{}, &models.ActivityStatus{}, &models.ActivityTransition{}, &models.ActivityCheck{})
+	cfg := &config.Config{App: config.AppConfig{ActivityCheck: config.ActivityCheckConfig{
+		MaxAttempts: 3,
+		Rewards: config.RewardsConfig{
+			AliveScore:    5,
+			StreakBonuses: []config.StreakBonusConfig{{Streak: 1, Bonus: 10}},
+		},
+	}}}
+	activity := NewActivityService(nil, cfg, db, NewCreditService(db, nil, NewPolicy(cfg.App.Economy)))
+	leaderboard := NewLeaderboardService(db)
+
+	if err := db.Create(&models.Credit{UserID: 1, Username: "a"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	status := &models.ActivityStatus{
+		UserID:          1,
+		Username:        "a",
+		State:           models.ActivityPending,
+		LastCheck:       time.Now(),
+		NextCheckTime:   time.Now().Add(time.Hour).UTC(),
+		Challenge:       "button",
+		ChallengeAnswer: "ok",
+	}
+	if err := db.Create(status).Error; err != nil {
+		t.Fatal(err)
+	}
+	// A missed check inside the window scores nothing
+	if err := db.Create(&models.ActivityCheck{UserID: 1, Username: "a", CheckTime: time.Now()}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if result := activity.HandleAliveResponse(1, "a", "ok"); result != AliveAccepted {
+		t.Fatalf("HandleAliveResponse = %v, want AliveAccepted", result)
+	}
+
+	var user models.Credit
+	if err := db.First(&user, "user_id = ?", 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, window := range []string{WindowAll, WindowDay, WindowMonth} {
+		page, err := leaderboard.Page(LeaderboardQuery{Board: BoardAlive, Window: window, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Entries) != 1 || page.Entries[0].Value != user.AliveScore || user.AliveScore != 15 {
+			t.Errorf("%s alive board = %+v with alive score %d, want the awarded 15", window, page.Entries, user.AliveScore)
+		}
+	}
+}
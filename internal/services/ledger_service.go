@@ -0,0 +1,373 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"social-credit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// AccountRef identifies an account by kind and owner. Accounts are created on
+// first use.
+type AccountRef struct {
+	Kind    string
+	OwnerID int64
+}
+
+func UserAccount(userID int64) AccountRef {
+	return AccountRef{Kind: models.AccountUser, OwnerID: userID}
+}
+
+func MintAccount() AccountRef {
+	return AccountRef{Kind: models.AccountMint}
+}
+
+func TreasuryAccount(chatID int64) AccountRef {
+	return AccountRef{Kind: models.AccountTreasury, OwnerID: chatID}
+}
+
+type LedgerService struct {
+	db *gorm.DB
+}
+
+func NewLedgerService(db *gorm.DB) *LedgerService {
+	return &LedgerService{db: db}
+}
+
+// Bootstrap opens a user account for every credit row that predates the
+// ledger, minting its current money as an opening balance. It only runs on a
+// database without any accounts, so money that later appears outside the
+// ledger is left for the audit to report.
+func (s *LedgerService) Bootstrap() error {
+	var accounts int64
+	if err := s.db.Model(&models.Account{}).Count(&accounts).Error; err != nil {
+		return err
+	}
+	if accounts > 0 {
+		return nil
+	}
+
+	var credits []models.Credit
+	err := s.db.
+		Where("NOT EXISTS (SELECT 1 FROM accounts WHERE accounts.kind = ? AND accounts.owner_id = credits.user_id)", models.AccountUser).
+		Find(&credits).Error
+	if err != nil {
+		return err
+	}
+
+	for _, credit := range credits {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			mint, err := s.account(tx, MintAccount())
+			if err != nil {
+				return err
+			}
+			account := models.Account{Kind: models.AccountUser, OwnerID: int64(credit.UserID), Balance: credit.Money}
+			if err := tx.Create(&account).Error; err != nil {
+				return err
+			}
+			if credit.Money == 0 {
+				return nil
+			}
+			if err := tx.Model(mint).UpdateColumn("balance", gorm.Expr("balance - ?", credit.Money)).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.LedgerEntry{
+				FromAccountID: mint.ID,
+				ToAccountID:   account.ID,
+				Amount:        credit.Money,
+				Kind:          models.EntryOpening,
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to open account for user %d: %w", credit.UserID, err)
+		}
+	}
+	return nil
+}
+
+// Move posts a single entry in its own transaction.
+func (s *LedgerService) Move(from, to AccountRef, amount int, kind, memo string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.Post(tx, from, to, amount, kind, memo)
+	})
+}
+
+// Post moves amount between two accounts inside tx. Only the mint may go
+// negative; user balances are mirrored into credits.money.
+func (s *LedgerService) Post(tx *gorm.DB, from, to AccountRef, amount int, kind, memo string) error {
+	if amount <= 0 {
+		return fmt.Errorf("invalid amount: %d", amount)
+	}
+	if from == to {
+		return fmt.Errorf("cannot move money from an account to itself")
+	}
+
+	fromAccount, err := s.account(tx, from)
+	if err != nil {
+		return err
+	}
+	toAccount, err := s.account(tx, to)
+	if err != nil {
+		return err
+	}
+
+	debit := tx.Model(&models.Account{}).Where("id = ?", fromAccount.ID)
+	if from.Kind != models.AccountMint {
+		debit = debit.Where("balance >= ?", amount)
+	}
+	debit = debit.UpdateColumn("balance", gorm.Expr("balance - ?", amount))
+	if debit.Error != nil {
+		return debit.Error
+	}
+	if debit.RowsAffected == 0 {
+		return ErrInsufficientBalance
+	}
+
+	if err := tx.Model(toAccount).UpdateColumn("balance", gorm.Expr("balance + ?", amount)).Error; err != nil {
+		return err
+	}
+
+	if err := s.mirrorUserBalance(tx, from, -amount); err != nil {
+		return err
+	}
+	if err := s.mirrorUserBalance(tx, to, amount); err != nil {
+		return err
+	}
+
+	return tx.Create(&models.LedgerEntry{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Kind:          kind,
+		Memo:          memo,
+	}).Error
+}
+
+// Open creates the account if it does not exist yet.
+func (s *LedgerService) Open(tx *gorm.DB, ref AccountRef) error {
+	_, err := s.account(tx, ref)
+	return err
+}
+
+// Balance returns the balance of the account, 0 if it was never used.
+func (s *LedgerService) Balance(ref AccountRef) (int, error) {
+	account, err := s.find(ref)
+	if err != nil || account == nil {
+		return 0, err
+	}
+	return account.Balance, nil
+}
+
+// find returns the account without creating it, or nil if it does not exist.
+func (s *LedgerService) find(ref AccountRef) (*models.Account, error) {
+	var account models.Account
+	err := s.db.Where("kind = ? AND owner_id = ?", ref.Kind, ref.OwnerID).Limit(1).Find(&account).Error
+	if err != nil || account.ID == 0 {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (s *LedgerService) account(tx *gorm.DB, ref AccountRef) (*models.Account, error) {
+	account := models.Account{Kind: ref.Kind, OwnerID: ref.OwnerID}
+	err := tx.Where("kind = ? AND owner_id = ?", ref.Kind, ref.OwnerID).FirstOrCreate(&account).Error
+	return &account, err
+}
+
+func (s *LedgerService) mirrorUserBalance(tx *gorm.DB, ref AccountRef, delta int) error {
+	if ref.Kind != models.AccountUser {
+		return nil
+	}
+	result := tx.Model(&models.Credit{}).
+		Where("user_id = ?", ref.OwnerID).
+		UpdateColumn("money", gorm.Expr("money + ?", delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user %d has no credit record", ref.OwnerID)
+	}
+	return nil
+}
+
+// StatementLine is a ledger entry seen from one account: Amount is positive
+// for money received and negative for money paid out.
+type StatementLine struct {
+	Entry        models.LedgerEntry
+	Amount       int
+	Counterparty models.Account
+}
+
+// Statement returns the most recent entries touching the account, newest
+// first.
+func (s *LedgerService) Statement(ref AccountRef, limit int) ([]StatementLine, error) {
+	account, err := s.find(ref)
+	if err != nil || account == nil {
+		return nil, err
+	}
+
+	var entries []models.LedgerEntry
+	err = s.db.Where("from_account_id = ? OR to_account_id = ?", account.ID, account.ID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.FromAccountID, e.ToAccountID)
+	}
+	var accounts []models.Account
+	if err := s.db.Where("id IN ?", ids).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]models.Account, len(accounts))
+	for _, a := range accounts {
+		byID[a.ID] = a
+	}
+
+	lines := make([]StatementLine, 0, len(entries))
+	for _, e := range entries {
+		line := StatementLine{Entry: e, Amount: e.Amount, Counterparty: byID[e.FromAccountID]}
+		if e.FromAccountID == account.ID {
+			line.Amount = -e.Amount
+			line.Counterparty = byID[e.ToAccountID]
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// AuditReport is the result of checking the ledger against the supply
+// invariant: money held by every non-mint account must equal minted - burned.
+type AuditReport struct {
+	Minted        int
+	Burned        int
+	Supply        int
+	Accounts      int
+	Entries       int64
+	Discrepancies []string
+}
+
+func (r *AuditReport) OK() bool {
+	return len(r.Discrepancies) == 0
+}
+
+func (r *AuditReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧾 Supply Audit:\nMinted: %d\nBurned: %d\nSupply: %d\nAccounts: %d\nEntries: %d\n",
+		r.Minted, r.Burned, r.Supply, r.Accounts, r.Entries)
+	if r.OK() {
+		b.WriteString("\n✅ Ledger balances, no discrepancies.")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n❌ %d discrepancies:\n", len(r.Discrepancies))
+	for _, d := range r.Discrepancies {
+		b.WriteString("- " + d + "\n")
+	}
+	return b.String()
+}
+
+type accountFlow struct {
+	AccountID int64
+	Net       int
+}
+
+// Audit recomputes every balance from the ledger and reports anything that
+// does not add up.
+func (s *LedgerService) Audit() (*AuditReport, error) {
+	report := &AuditReport{}
+
+	var accounts []models.Account
+	if err := s.db.Order("id").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	report.Accounts = len(accounts)
+
+	if err := s.db.Model(&models.LedgerEntry{}).Count(&report.Entries).Error; err != nil {
+		return nil, err
+	}
+
+	var flows []accountFlow
+	err := s.db.Raw(`SELECT account_id, SUM(delta) AS net FROM (
+			SELECT to_account_id AS account_id, amount AS delta FROM ledger_entries
+			UNION ALL
+			SELECT from_account_id AS account_id, -amount AS delta FROM ledger_entries
+		) flows GROUP BY account_id`).Scan(&flows).Error
+	if err != nil {
+		return nil, err
+	}
+	net := make(map[int64]int, len(flows))
+	for _, f := range flows {
+		net[f.AccountID] = f.Net
+	}
+
+	var credits []models.Credit
+	if err := s.db.Find(&credits).Error; err != nil {
+		return nil, err
+	}
+	money := make(map[int64]int, len(credits))
+	for _, c := range credits {
+		money[int64(c.UserID)] = c.Money
+	}
+
+	for _, account := range accounts {
+		if account.Kind == models.AccountMint {
+			var minted, burned int
+			err := s.db.Model(&models.LedgerEntry{}).Where("from_account_id = ?", account.ID).Select("COALESCE(SUM(amount), 0)").Scan(&minted).Error
+			if err != nil {
+				return nil, err
+			}
+			err = s.db.Model(&models.LedgerEntry{}).Where("to_account_id = ?", account.ID).Select("COALESCE(SUM(amount), 0)").Scan(&burned).Error
+			if err != nil {
+				return nil, err
+			}
+			report.Minted += minted
+			report.Burned += burned
+		} else {
+			report.Supply += account.Balance
+			if account.Balance < 0 {
+				report.add("%s account %d has negative balance %d", account.Kind, account.OwnerID, account.Balance)
+			}
+		}
+
+		if account.Balance != net[account.ID] {
+			report.add("%s account %d balance %d does not match ledger %d", account.Kind, account.OwnerID, account.Balance, net[account.ID])
+		}
+
+		if account.Kind == models.AccountUser {
+			m, ok := money[account.OwnerID]
+			if !ok {
+				report.add("user account %d has no credit row", account.OwnerID)
+			} else if m != account.Balance {
+				report.add("user %d money %d does not match account balance %d", account.OwnerID, m, account.Balance)
+			}
+			delete(money, account.OwnerID)
+		}
+	}
+
+	// Users get their account with their first money, so an empty user
+	// without one is fine
+	for _, c := range credits {
+		if m, ok := money[int64(c.UserID)]; ok && m != 0 {
+			report.add("user %d has %d money but no account", c.UserID, m)
+		}
+	}
+
+	if report.Supply != report.Minted-report.Burned {
+		report.add("supply %d does not equal minted - burned (%d)", report.Supply, report.Minted-report.Burned)
+	}
+
+	return report, nil
+}
+
+func (r *AuditReport) add(format string, args ...any) {
+	r.Discrepancies = append(r.Discrepancies, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"social-credit/internal/models"
+)
+
+// newTestDB returns an empty in-memory database with the given models
+// migrated.
+func newTestDB(t *testing.T, tables ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a separate database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestLedger returns a ledger whose users all have an empty account, as
+// CreditService.InitializeUser leaves them.
+func newTestLedger(t *testing.T, users ...models.Credit) (*gorm.DB, *LedgerService) {
+	t.Helper()
+	db := newTestDB(t, &models.Credit{}, &models.Account{}, &models.LedgerEntry{})
+	ledger := NewLedgerService(db)
+	for _, user := range users {
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := ledger.Open(db, UserAccount(int64(user.UserID))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db, ledger
+}
+
+func balance(t *testing.T, ledger *LedgerService, ref AccountRef) int {
+	t.Helper()
+	b, err := ledger.Balance(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPostConservesSupply(t *testing.T) {
+	type move struct {
+		from, to AccountRef
+		amount   int
+	}
+	tests := []struct {
+		name  string
+		moves []move
+		want  map[AccountRef]int
+	}{
+		{
+			name:  "grant",
+			moves: []move{{MintAccount(), UserAccount(1), 10}},
+			want:  map[AccountRef]int{UserAccount(1): 10, MintAccount(): -10},
+		},
+		{
+			name: "transfer with tax",
+			moves: []move{
+				{MintAccount(), UserAccount(1), 10},
+				{UserAccount(1), UserAccount(2), 4},
+				{UserAccount(2), TreasuryAccount(-100), 1},
+			},
+			want: map[AccountRef]int{UserAccount(1): 6, UserAccount(2): 3, TreasuryAccount(-100): 1},
+		},
+		{
+			name: "burn",
+			moves: []move{
+				{MintAccount(), UserAccount(1), 10},
+				{UserAccount(1), MintAccount(), 10},
+			},
+			want: map[AccountRef]int{UserAccount(1): 0, MintAccount(): 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, ledger := newTestLedger(t, models.Credit{UserID: 1, Username: "a"}, models.Credit{UserID: 2, Username: "b"})
+			for _, m := range tt.moves {
+				if err := ledger.Move(m.from, m.to, m.amount, models.EntryGrant, ""); err != nil {
+					t.Fatalf("Move(%v, %v, %d): %v", m.from, m.to, m.amount, err)
+				}
+			}
+
+			for ref, want := range tt.want {
+				if got := balance(t, ledger, ref); got != want {
+					t.Errorf("balance of %v = %d, want %d", ref, got, want)
+				}
+			}
+
+			var user models.Credit
+			if err := db.First(&user, "user_id = ?", 1).Error; err != nil {
+				t.Fatal(err)
+			}
+			if want := balance(t, ledger, UserAccount(1)); user.Money != want {
+				t.Errorf("credits.money = %d, want the account balance %d", user.Money, want)
+			}
+
+			report, err := ledger.Audit()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !report.OK() {
+				t.Errorf("audit found discrepancies: %v", report.Discrepancies)
+			}
+			if report.Supply != report.Minted-report.Burned {
+				t.Errorf("supply %d != minted %d - burned %d", report.Supply, report.Minted, report.Burned)
+			}
+		})
+	}
+}
+
+func TestPostRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to AccountRef
+		amount   int
+		wantErr  error
+	}{
+		{name: "overdraft", from: UserAccount(1), to: UserAccount(2), amount: 6, wantErr: ErrInsufficientBalance},
+		{name: "empty treasury", from: TreasuryAccount(-100), to: UserAccount(1), amount: 1, wantErr: ErrInsufficientBalance},
+		{name: "zero amount", from: UserAccount(1), to: UserAccount(2), amount: 0},
+		{name: "negative amount", from: UserAccount(1), to: UserAccount(2), amount: -1},
+		{name: "self transfer", from: UserAccount(1), to: UserAccount(1), amount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, ledger := newTestLedger(t, models.Credit{UserID: 1, Username: "a"}, models.Credit{UserID: 2, Username: "b"})
+			if err := ledger.Move(MintAccount(), UserAccount(1), 5, models.EntryGrant, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			err := ledger.Move(tt.from, tt.to, tt.amount, models.EntryTransfer, "")
+			if err == nil {
+				t.Fatal("Move succeeded, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Move error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := balance(t, ledger, UserAccount(1)); got != 5 {
+				t.Errorf("balance of user 1 = %d, want 5", got)
+			}
+			var entries int64
+			db.Model(&models.LedgerEntry{}).Count(&entries)
+			if entries != 1 {
+				t.Errorf("%d ledger entries, want only the grant", entries)
+			}
+		})
+	}
+}
+
+func TestAuditDiscrepancies(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(db *gorm.DB) error
+		want   string
+	}{
+		{
+			name: "money changed outside the ledger",
+			tamper: func(db *gorm.DB) error {
+				return db.Model(&models.Credit{}).Where("user_id = ?", 1).Update("money", 50).Error
+			},
+			want: "user 1 money 50 does not match account balance 10",
+		},
+		{
+			name: "account balance changed outside the ledger",
+			tamper: func(db *gorm.DB) error {
+				return db.Model(&models.Account{}).Where("kind = ? AND owner_id = ?", models.AccountUser, 1).Update("balance", 12).Error
+			},
+			want: "user account 1 balance 12 does not match ledger 10",
+		},
+		{
+			name: "user without account",
+			tamper: func(db *gorm.DB) error {
+				return db.Create(&models.Credit{UserID: 3, Username: "c", Money: 5}).Error
+			},
+			want: "user 3 has 5 money but no account",
+		},
+		{
+			name: "account without user",
+			tamper: func(db *gorm.DB) error {
+				return db.Delete(&models.Credit{}, "user_id = ?", 1).Error
+			},
+			want: "user account 1 has no credit row",
+		},
+		{
+			name: "negative balance",
+			tamper: func(db *gorm.DB) error {
+				return db.Model(&models.Account{}).Where("kind = ?", models.AccountTreasury).Update("balance", -1).Error
+			},
+			want: "treasury account -100 has negative balance -1",
+		},
+		{
+			name: "supply out of thin air",
+			tamper: func(db *gorm.DB) error {
+				return db.Model(&models.Account{}).Where("kind = ?", models.AccountTreasury).Update("balance", 7).Error
+			},
+			want: "does not equal minted - burned",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, ledger := newTestLedger(t, models.Credit{UserID: 1, Username: "a"})
+			if err := ledger.Move(MintAccount(), UserAccount(1), 10, models.EntryGrant, ""); err != nil {
+				t.Fatal(err)
+			}
+			if err := ledger.Move(MintAccount(), TreasuryAccount(-100), 2, models.EntryGrant, ""); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.tamper(db); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := ledger.Audit()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range report.Discrepancies {
+				if strings.Contains(d, tt.want) {
+					return
+				}
+			}
+			t.Errorf("discrepancies %q do not mention %q", report.Discrepancies, tt.want)
+		})
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	db := newTestDB(t, &models.Credit{}, &models.Account{}, &models.LedgerEntry{})
+	ledger := NewLedgerService(db)
+	users := []models.Credit{{UserID: 1, Username: "a", Money: 7}, {UserID: 2, Username: "b"}}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	if got := balance(t, ledger, UserAccount(1)); got != 7 {
+		t.Errorf("opening balance of user 1 = %d, want 7", got)
+	}
+	report, err := ledger.Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Minted != 7 {
+		t.Errorf("audit after bootstrap = %+v, want 7 minted and no discrepancies", report)
+	}
+
+	// Money that shows up once the ledger exists is not legitimised
+	if err := db.Create(&models.Credit{UserID: 3, Username: "c", Money: 5}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	report, err = ledger.Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || report.Minted != 7 {
+		t.Errorf("audit after second bootstrap = %+v, want the unrecorded money reported", report)
+	}
+}
+
+func TestAuditIgnoresEmptyUsersWithoutAccount(t *testing.T) {
+	db, ledger := newTestLedger(t, models.Credit{UserID: 1, Username: "a"})
+	// Created after the bootstrap and never paid, so never given an account
+	if err := db.Create(&models.Credit{UserID: 2, Username: "b"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ledger.Audit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Errorf("audit found discrepancies: %v", report.Discrepancies)
+	}
+}
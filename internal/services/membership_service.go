@@ -0,0 +1,93 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"social-credit/internal/models"
+)
+
+// MembershipService tracks which users belong to which chat so every group
+// can rank only its own citizens.
+type MembershipService struct {
+	db *gorm.DB
+
+	mu sync.Mutex
+	// written is when each membership was last saved, so members who keep
+	// chatting are not written for every message
+	written map[membershipKey]time.Time
+}
+
+type membershipKey struct {
+	chatID, userID int64
+}
+
+func NewMembershipService(db *gorm.DB) *MembershipService {
+	return &MembershipService{db: db, written: make(map[membershipKey]time.Time)}
+}
+
+// Bootstrap seeds an empty membership table from the chats users voted or
+// were voted on in, so group leaderboards are not empty right after the
+// upgrade.
+func (s *MembershipService) Bootstrap() error {
+	var count int64
+	if err := s.db.Model(&models.Membership{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+
+	var memberships []models.Membership
+	err := s.db.Raw(`SELECT DISTINCT chat_id, user_id FROM credit_events WHERE chat_id < 0 AND user_id <> 0
+		UNION
+		SELECT DISTINCT chat_id, actor_id AS user_id FROM credit_events WHERE chat_id < 0 AND actor_id <> 0`).
+		Scan(&memberships).Error
+	if err != nil || len(memberships) == 0 {
+		return err
+	}
+	for i := range memberships {
+		memberships[i].Status = models.MemberActive
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(memberships, 500).Error
+}
+
+// Joined records that userID is in chatID.
+func (s *MembershipService) Joined(chatID, userID int64) error {
+	return s.set(chatID, userID, models.MemberActive)
+}
+
+// Spoke records that userID sent a message in chatID. Like the last seen time
+// of activity checks, it is written at most once a minute per member.
+func (s *MembershipService) Spoke(chatID, userID int64) error {
+	s.mu.Lock()
+	written := s.written[membershipKey{chatID, userID}]
+	s.mu.Unlock()
+	if time.Since(written) < time.Minute {
+		return nil
+	}
+	return s.Joined(chatID, userID)
+}
+
+// Left records that userID is no longer in chatID.
+func (s *MembershipService) Left(chatID, userID int64) error {
+	return s.set(chatID, userID, models.MemberLeft)
+}
+
+func (s *MembershipService) set(chatID, userID int64, status string) error {
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "updated_at"}),
+	}).Create(&models.Membership{ChatID: chatID, UserID: userID, Status: status}).Error
+
+	key := membershipKey{chatID, userID}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil || status != models.MemberActive {
+		// The next message must bring a member who left back
+		delete(s.written, key)
+		return err
+	}
+	s.written[key] = time.Now()
+	return nil
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+
+	"social-credit/internal/models"
+)
+
+func TestMembershipSpoke(t *testing.T) {
+	db := newTestDB(t, &models.Membership{})
+	s := NewMembershipService(db)
+	status := func() string {
+		t.Helper()
+		var m models.Membership
+		if err := db.First(&m, "chat_id = ? AND user_id = ?", -100, 1).Error; err != nil {
+			t.Fatal(err)
+		}
+		return m.Status
+	}
+
+	if err := s.Spoke(-100, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(); got != models.MemberActive {
+		t.Fatalf("status after first message = %s, want %s", got, models.MemberActive)
+	}
+
+	// Messages within the minute are not written
+	if err := db.Model(&models.Membership{}).Where("user_id = ?", 1).Update("status", "marker").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Spoke(-100, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(); got != "marker" {
+		t.Errorf("status after second message = %s, want it left alone", got)
+	}
+
+	// A member who left is back with their next message
+	if err := s.Left(-100, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Spoke(-100, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(); got != models.MemberActive {
+		t.Errorf("status after leaving and speaking = %s, want %s", got, models.MemberActive)
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+// maxDebounces caps how many debounce periods a refresh can be put off by
+// a steady stream of changes.
+const maxDebounces = 5
+
+// PinnedService tracks the pinned leaderboard of every chat and tells its
+// listeners to refresh them once balances stop changing. Writers call Touch
+// after committing, so a burst of votes causes a single edit.
+type PinnedService struct {
+	db       *gorm.DB
+	debounce time.Duration
+
+	mu        sync.Mutex
+	timer     *time.Timer
+	since     time.Time
+	chats     map[int64]bool
+	listeners []func(models.PinnedBoard)
+}
+
+func NewPinnedService(config *config.Config, db *gorm.DB) *PinnedService {
+	return &PinnedService{
+		db:       db,
+		debounce: time.Duration(config.App.Pinned.Debounce) * time.Second,
+		chats:    map[int64]bool{},
+	}
+}
+
+// OnRefresh registers fn to be called with every pinned board that needs to
+// be redrawn.
+func (s *PinnedService) OnRefresh(fn func(models.PinnedBoard)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Touch schedules a refresh of the pinned board of chatID, or of every
+// pinned board for 0, after a committed change. The refresh waits until no
+// change has been made for the debounce period, but at most maxDebounces
+// periods after the first change.
+func (s *PinnedService) Touch(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chats[chatID] = true
+
+	if s.timer == nil {
+		s.since = time.Now()
+		s.timer = time.AfterFunc(s.debounce, s.refresh)
+		return
+	}
+	if time.Since(s.since) < maxDebounces*s.debounce {
+		s.timer.Reset(s.debounce)
+	}
+}
+
+func (s *PinnedService) refresh() {
+	s.mu.Lock()
+	s.timer = nil
+	chats := s.chats
+	s.chats = map[int64]bool{}
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	boards, err := s.Boards()
+	if err != nil {
+		log.Printf("Error loading pinned leaderboards: %v", err)
+		return
+	}
+	for _, board := range boards {
+		if !chats[0] && !chats[board.ChatID] {
+			continue
+		}
+		for _, fn := range listeners {
+			fn(board)
+		}
+	}
+}
+
+func (s *PinnedService) Boards() ([]models.PinnedBoard, error) {
+	var boards []models.PinnedBoard
+	err := s.db.Find(&boards).Error
+	return boards, err
+}
+
+// Get returns the pinned board of chatID, or nil if the chat has none.
+func (s *PinnedService) Get(chatID int64) (*models.PinnedBoard, error) {
+	var board models.PinnedBoard
+	err := s.db.Where("chat_id = ?", chatID).First(&board).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// Enable records messageID as the pinned board of chatID, replacing any
+// previous one.
+func (s *PinnedService) Enable(chatID int64, messageID int, board string) error {
+	return s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.PinnedBoard{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Board:     board,
+	}).Error
+}
+
+func (s *PinnedService) Disable(chatID int64) error {
+	return s.db.Where("chat_id = ?", chatID).Delete(&models.PinnedBoard{}).Error
+}
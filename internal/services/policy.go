@@ -0,0 +1,93 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+// Policy turns the economy section of the config into concrete amounts. The
+// live bot and the offline simulator both go through it so a simulated config
+// behaves exactly like a deployed one.
+type Policy struct {
+	cfg config.EconomyConfig
+}
+
+func NewPolicy(cfg config.EconomyConfig) Policy {
+	return Policy{cfg: cfg}
+}
+
+// NewLivePolicy returns the policy of the running bot. Vote weights, the
+// transfer amount and decay are only there to be tried out with the
+// simulator, so the bot keeps their original values whatever the config says.
+// The fraud fine and the transfer tax apply as configured.
+func NewLivePolicy(cfg config.EconomyConfig) Policy {
+	cfg.Votes.Positive = 1
+	cfg.Votes.Negative = -1
+	cfg.Votes.FraudPenalty = -3
+	cfg.Transfer.Amount = 1
+	cfg.Decay = config.DecayConfig{}
+	return NewPolicy(cfg)
+}
+
+// VoteAmount returns the SocialCredit change for a credit event kind.
+func (p Policy) VoteAmount(kind string) int {
+	switch kind {
+	case models.CreditPositive:
+		return p.cfg.Votes.Positive
+	case models.CreditNegative:
+		return p.cfg.Votes.Negative
+	case models.CreditFraud:
+		return p.cfg.Votes.FraudPenalty
+	}
+	return 0
+}
+
+func (p Policy) FraudFine() int {
+	return p.cfg.Votes.FraudFine
+}
+
+func (p Policy) TransferAmount() int {
+	return p.cfg.Transfer.Amount
+}
+
+// TransferTax returns the part of amount withheld as tax, rounded down.
+func (p Policy) TransferTax(amount int) int {
+	return amount * p.cfg.Transfer.TaxPercent / 100
+}
+
+// Decay returns the daily change that moves credit towards zero.
+func (p Policy) Decay(credit int) int {
+	return -credit * p.cfg.Decay.PercentPerDay / 100
+}
+
+// DailyReward returns the /daily payout for the given streak length.
+func (p Policy) DailyReward(streak int) int {
+	bonus := p.cfg.Daily.StreakBonusPercent * (min(streak, max(p.cfg.Daily.MaxStreak, 1)) - 1)
+	return p.cfg.Daily.Amount * (100 + bonus) / 100
+}
+
+func (p Policy) WorkCooldown() time.Duration {
+	return time.Duration(p.cfg.Work.Cooldown) * time.Second
+}
+
+// WorkReward returns the /work payout scaled by the tier of credit.
+func (p Policy) WorkReward(credit int) int {
+	return int(float64(p.cfg.Work.Amount) * p.Tier(credit).Multiplier)
+}
+
+// Tier returns the highest configured tier credit qualifies for. Without any
+// configured tiers everybody is an unnamed tier with multiplier 1.
+func (p Policy) Tier(credit int) config.TierConfig {
+	tier := config.TierConfig{Multiplier: 1, MinCredit: math.MinInt}
+	found := false
+	for _, t := range p.cfg.Tiers {
+		if credit >= t.MinCredit && (!found || t.MinCredit > tier.MinCredit) {
+			tier = t
+			found = true
+		}
+	}
+	return tier
+}
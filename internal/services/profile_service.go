@@ -0,0 +1,96 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"social-credit/internal/models"
+)
+
+type VoteCounts struct {
+	Positive int64
+	Negative int64
+}
+
+// Profile is everything known about a single citizen.
+type Profile struct {
+	Credit        models.Credit
+	Tier          string
+	Ranks         map[string]int
+	VotesGiven    VoteCounts
+	VotesReceived VoteCounts
+	LastResponse  time.Time
+	ActivityState string
+	SnoozedUntil  time.Time
+	Badges        []models.Badge
+}
+
+type ProfileService struct {
+	db          *gorm.DB
+	leaderboard *LeaderboardService
+	policy      Policy
+}
+
+func NewProfileService(db *gorm.DB, leaderboard *LeaderboardService, policy Policy) *ProfileService {
+	return &ProfileService{db: db, leaderboard: leaderboard, policy: policy}
+}
+
+// GetProfile returns the profile of userID. A non-zero chatID ranks them
+// among the members of that chat only, as its leaderboards do.
+func (s *ProfileService) GetProfile(userID, chatID int64) (*Profile, error) {
+	profile := &Profile{Ranks: make(map[string]int)}
+	if err := s.db.First(&profile.Credit, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	profile.Tier = s.policy.Tier(profile.Credit.Credit).Name
+
+	for _, board := range []string{BoardCredit, BoardMoney, BoardAlive} {
+		entry, err := s.leaderboard.Rank(LeaderboardQuery{Board: board, ChatID: chatID}, userID)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			profile.Ranks[board] = entry.Rank
+		}
+	}
+
+	var err error
+	if profile.VotesGiven, err = s.voteCounts("actor_id = ? AND user_id <> actor_id", userID); err != nil {
+		return nil, err
+	}
+	if profile.VotesReceived, err = s.voteCounts("user_id = ? AND user_id <> actor_id", userID); err != nil {
+		return nil, err
+	}
+
+	var status models.ActivityStatus
+	err = s.db.Where("user_id = ?", userID).First(&status).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	profile.LastResponse = status.LastResponse
+	profile.ActivityState = status.State
+	profile.SnoozedUntil = status.SnoozedUntil
+
+	if err := s.db.Where("user_id = ?", userID).Order("season_id").Find(&profile.Badges).Error; err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *ProfileService) voteCounts(query string, userID int64) (VoteCounts, error) {
+	var counts VoteCounts
+	err := s.db.Model(&models.CreditEvent{}).
+		Where(query, userID).
+		Where("kind = ?", models.CreditPositive).
+		Count(&counts.Positive).Error
+	if err != nil {
+		return counts, err
+	}
+	err = s.db.Model(&models.CreditEvent{}).
+		Where(query, userID).
+		Where("kind = ?", models.CreditNegative).
+		Count(&counts.Negative).Error
+	return counts, err
+}
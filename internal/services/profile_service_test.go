@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+func TestProfileRankInChat(t *testing.T) {
+	db := newTestDB(t, &models.Credit{}, &models.Membership{}, &models.CreditEvent{}, &models.ActivityStatus{}, &models.Badge{})
+	users := []models.Credit{
+		{UserID: 1, Username: "a", Credit: 30},
+		{UserID: 2, Username: "b", Credit: 20},
+		{UserID: 3, Username: "c", Credit: 10},
+	}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	memberships := NewMembershipService(db)
+	for _, userID := range []int64{2, 3} {
+		if err := memberships.Joined(-100, userID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewProfileService(db, NewLeaderboardService(db), NewPolicy(config.EconomyConfig{}))
+
+	tests := []struct {
+		name   string
+		chatID int64
+		want   int
+	}{
+		{name: "everywhere", chatID: 0, want: 3},
+		{name: "in the group", chatID: -100, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := s.GetProfile(3, tt.chatID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := profile.Ranks[BoardCredit]; got != tt.want {
+				t.Errorf("credit rank = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+
+	"social-credit/internal/config"
+	"social-credit/internal/models"
+)
+
+// SeasonResult is the archived outcome of an ended season.
+type SeasonResult struct {
+	Season    models.Season
+	Standings []models.SeasonStanding
+	Badges    []models.Badge
+}
+
+type SeasonService struct {
+	bot           *tgbotapi.BotAPI
+	config        *config.Config
+	scheduler     *gocron.Scheduler
+	db            *gorm.DB
+	creditService *CreditService
+}
+
+func NewSeasonService(bot *tgbotapi.BotAPI, config *config.Config, db *gorm.DB, creditService *CreditService) *SeasonService {
+	return &SeasonService{
+		bot:           bot,
+		config:        config,
+		scheduler:     gocron.NewScheduler(time.UTC),
+		db:            db,
+		creditService: creditService,
+	}
+}
+
+func (s *SeasonService) Start() error {
+	if _, err := s.Current(); err != nil {
+		return fmt.Errorf("failed to load current season: %w", err)
+	}
+	_, err := s.scheduler.Every(1).Hour().Do(s.endExpiredSeason)
+	if err != nil {
+		return fmt.Errorf("failed to schedule season checks: %w", err)
+	}
+	s.scheduler.StartAsync()
+	return nil
+}
+
+func (s *SeasonService) Stop() {
+	s.scheduler.Stop()
+}
+
+// Current returns the running season, starting the first one if there has
+// never been a season.
+func (s *SeasonService) Current() (*models.Season, error) {
+	var season models.Season
+	err := s.db.Where("ended_at IS NULL OR ended_at = ?", time.Time{}).Order("number DESC").First(&season).Error
+	if err == nil {
+		return &season, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	season = models.Season{Number: 1, StartedAt: time.Now()}
+	if s.config.App.Seasons.EndsAt != "" {
+		endsAt, err := time.Parse("2006-01-02", s.config.App.Seasons.EndsAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seasons.ends_at: %w", err)
+		}
+		season.EndsAt = endsAt
+	}
+	if err := s.db.Create(&season).Error; err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// EndSeason archives the final standings of the running season, awards
+// badges to the top finishers, resets SocialCredit and starts the next
+// season.
+func (s *SeasonService) EndSeason() (*SeasonResult, error) {
+	season, err := s.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SeasonResult{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var credits []models.Credit
+		if err := tx.Order("credit DESC, user_id").Find(&credits).Error; err != nil {
+			return err
+		}
+
+		for i, c := range credits {
+			result.Standings = append(result.Standings, models.SeasonStanding{
+				SeasonID:   season.ID,
+				UserID:     int64(c.UserID),
+				Username:   c.Username,
+				Rank:       i + 1,
+				Credit:     c.Credit,
+				Money:      c.Money,
+				AliveScore: c.AliveScore,
+			})
+		}
+		if len(result.Standings) > 0 {
+			if err := tx.Create(&result.Standings).Error; err != nil {
+				return err
+			}
+		}
+
+		for i, emoji := range s.config.App.Seasons.Badges {
+			if i >= len(credits) {
+				break
+			}
+			result.Badges = append(result.Badges, models.Badge{
+				UserID:   int64(credits[i].UserID),
+				SeasonID: season.ID,
+				Rank:     i + 1,
+				Emoji:    emoji,
+			})
+		}
+		if len(result.Badges) > 0 {
+			if err := tx.Create(&result.Badges).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, c := range credits {
+			amount := -c.Credit * s.config.App.Seasons.ResetPercent / 100
+			if amount == 0 {
+				continue
+			}
+			err := s.creditService.addCredit(tx, &models.CreditEvent{
+				UserID: int64(c.UserID),
+				Kind:   models.CreditSeason,
+				Amount: amount,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		season.EndedAt = now
+		if err := tx.Save(season).Error; err != nil {
+			return err
+		}
+
+		next := models.Season{Number: season.Number + 1, StartedAt: now}
+		if days := s.config.App.Seasons.LengthDays; days > 0 {
+			next.EndsAt = now.AddDate(0, 0, days)
+		}
+		return tx.Create(&next).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.creditService.changed(0)
+
+	result.Season = *season
+	return result, nil
+}
+
+// HallOfFame returns the archived results of the most recent ended seasons,
+// newest first, with the top finishers of each.
+func (s *SeasonService) HallOfFame(seasons, finishers int) ([]SeasonResult, error) {
+	var ended []models.Season
+	err := s.db.Where("ended_at IS NOT NULL AND ended_at > ?", time.Time{}).
+		Order("number DESC").
+		Limit(seasons).
+		Find(&ended).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SeasonResult, 0, len(ended))
+	for _, season := range ended {
+		result := SeasonResult{Season: season}
+		err := s.db.Where("season_id = ? AND rank <= ?", season.ID, finishers).
+			Order("rank").
+			Find(&result.Standings).Error
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.Where("season_id = ?", season.ID).Order("rank").Find(&result.Badges).Error; err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *SeasonService) endExpiredSeason() {
+	season, err := s.Current()
+	if err != nil {
+		log.Printf("Error loading current season: %v", err)
+		return
+	}
+	if season.EndsAt.IsZero() || time.Now().Before(season.EndsAt) {
+		return
+	}
+
+	result, err := s.EndSeason()
+	if err != nil {
+		log.Printf("Error ending season %d: %v", season.Number, err)
+		return
+	}
+
+	chatID, err := strconv.ParseInt(s.config.App.Seasons.AnnounceChat, 10, 64)
+	if err != nil {
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, FormatSeasonResult(result, len(s.config.App.Seasons.Badges)))
+	if _, err := s.bot.Send(msg); err != nil {
+		log.Printf("Error announcing season results: %v", err)
+	}
+}
+
+// FormatSeasonResult renders the top finishers of an ended season.
+func FormatSeasonResult(result *SeasonResult, finishers int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🏁 Season %d has ended!\n", result.Season.Number)
+	for _, standing := range result.Standings {
+		if standing.Rank > max(finishers, 3) {
+			break
+		}
+		fmt.Fprintf(&b, "%s%d. @%s — %d\n", badgeFor(result.Badges, standing.UserID), standing.Rank, standing.Username, standing.Credit)
+	}
+	return b.String()
+}
+
+func badgeFor(badges []models.Badge, userID int64) string {
+	for _, badge := range badges {
+		if badge.UserID == userID {
+			return badge.Emoji + " "
+		}
+	}
+	return ""
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"social-credit/internal/models"
+)
+
+type Standing struct {
+	UserID   int64
+	Username string
+	Credit   int
+	Money    int
+}
+
+type Standings []Standing
+
+func (s Standings) Credits() []int {
+	values := make([]int, len(s))
+	for i, st := range s {
+		values[i] = st.Credit
+	}
+	return values
+}
+
+func (s Standings) Money() []int {
+	values := make([]int, len(s))
+	for i, st := range s {
+		values[i] = st.Money
+	}
+	return values
+}
+
+// Top returns the n best standings by the given value.
+func (s Standings) Top(n int, value func(Standing) int) Standings {
+	sorted := slices.Clone(s)
+	slices.SortStableFunc(sorted, func(a, b Standing) int {
+		return cmp.Compare(value(b), value(a))
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// Simulator replays the recorded vote and transfer history under an
+// alternative economy policy without touching the database.
+type Simulator struct {
+	db *gorm.DB
+}
+
+func NewSimulator(db *gorm.DB) *Simulator {
+	return &Simulator{db: db}
+}
+
+// Actual returns the standings currently stored in the database.
+func (s *Simulator) Actual() (Standings, error) {
+	var credits []models.Credit
+	if err := s.db.Order("user_id").Find(&credits).Error; err != nil {
+		return nil, err
+	}
+
+	standings := make(Standings, len(credits))
+	for i, c := range credits {
+		standings[i] = Standing{UserID: int64(c.UserID), Username: c.Username, Credit: c.Credit, Money: c.Money}
+	}
+	return standings, nil
+}
+
+// Run replays history under policy, resetting resetPercent of every balance
+// at the end of each recorded season. SocialCredit that predates the
+// recorded events is carried over unchanged as each user's starting balance.
+//
+// Everything that depends on the policy is recomputed: votes, daily decay,
+// transfer amounts and taxes, fraud fines and season resets. Transfers do not
+// record their chat, so simulated taxes go to a treasury of their own.
+func (s *Simulator) Run(policy Policy, resetPercent int) (Standings, error) {
+	var credits []models.Credit
+	if err := s.db.Order("user_id").Find(&credits).Error; err != nil {
+		return nil, err
+	}
+	var events []models.CreditEvent
+	if err := s.db.Order("created_at, id").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	var entries []models.LedgerEntry
+	if err := s.db.Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	var accounts []models.Account
+	if err := s.db.Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	// Snapshots from before seasons have none
+	var resets []time.Time
+	if s.db.Migrator().HasTable(&models.Season{}) {
+		err := s.db.Model(&models.Season{}).
+			Where("ended_at IS NOT NULL AND ended_at > ?", time.Time{}).
+			Order("ended_at").
+			Pluck("ended_at", &resets).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	refs := make(map[int64]AccountRef, len(accounts))
+	for _, a := range accounts {
+		refs[a.ID] = AccountRef{Kind: a.Kind, OwnerID: a.OwnerID}
+	}
+
+	credit := make(map[int64]int, len(credits))
+	for _, c := range credits {
+		credit[int64(c.UserID)] = c.Credit
+	}
+	for _, e := range events {
+		credit[e.UserID] -= e.Amount
+	}
+
+	// advance applies the midnight decays and season resets up to t in
+	// the order they happened
+	var day time.Time
+	advance := func(t time.Time) {
+		if day.IsZero() {
+			day = t.UTC().Truncate(24 * time.Hour)
+		}
+		for {
+			next := day.Add(24 * time.Hour)
+			if len(resets) > 0 && !resets[0].After(t) && resets[0].Before(next) {
+				resets = resets[1:]
+				for userID, c := range credit {
+					credit[userID] = c - c*resetPercent/100
+				}
+				continue
+			}
+			if next.After(t) {
+				return
+			}
+			day = next
+			for userID, c := range credit {
+				credit[userID] = c + policy.Decay(c)
+			}
+		}
+	}
+
+	money := make(map[AccountRef]int, len(accounts))
+	move := func(from, to AccountRef, amount int) bool {
+		if amount <= 0 || (from.Kind != models.AccountMint && money[from] < amount) {
+			return false
+		}
+		money[from] -= amount
+		money[to] += amount
+		return true
+	}
+
+	for len(events) > 0 || len(entries) > 0 {
+		if len(entries) == 0 || (len(events) > 0 && events[0].CreatedAt.Before(entries[0].CreatedAt)) {
+			e := events[0]
+			events = events[1:]
+			advance(e.CreatedAt)
+			switch e.Kind {
+			case models.CreditDecay, models.CreditSeason:
+				// Recomputed from the policy by advance
+			case models.CreditFraud:
+				credit[e.UserID] += policy.VoteAmount(e.Kind)
+				user := UserAccount(e.UserID)
+				move(user, TreasuryAccount(e.ChatID), min(policy.FraudFine(), money[user]))
+			case models.CreditPositive, models.CreditNegative:
+				credit[e.UserID] += policy.VoteAmount(e.Kind)
+			default:
+				credit[e.UserID] += e.Amount
+			}
+			continue
+		}
+
+		e := entries[0]
+		entries = entries[1:]
+		advance(e.CreatedAt)
+		from, to := refs[e.FromAccountID], refs[e.ToAccountID]
+		switch {
+		case e.Kind == models.EntryTax:
+			// Recomputed from the policy alongside each transfer
+		case e.Kind == models.EntryFine && e.Memo == fraudFineMemo:
+			// Recomputed from the policy alongside each fraud
+		case e.Kind == models.EntryTransfer:
+			amount := policy.TransferAmount()
+			if move(from, to, amount) {
+				move(to, TreasuryAccount(0), policy.TransferTax(amount))
+			}
+		default:
+			move(from, to, e.Amount)
+		}
+	}
+	if !day.IsZero() {
+		advance(time.Now())
+	}
+
+	standings := make(Standings, len(credits))
+	for i, c := range credits {
+		userID := int64(c.UserID)
+		standings[i] = Standing{
+			UserID:   userID,
+			Username: c.Username,
+			Credit:   credit[userID],
+			Money:    money[UserAccount(userID)],
+		}
+	}
+	return standings, nil
+}
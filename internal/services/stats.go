@@ -0,0 +1,107 @@
+package services
+
+import (
+	"math"
+	"slices"
+)
+
+// Distribution metrics shared by the economy report and the policy simulator.
+
+func Mean(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	return float64(sum) / float64(len(values))
+}
+
+func Median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	return float64(sorted[mid])
+}
+
+// Gini returns the Gini coefficient of values, 0 for perfect equality and
+// approaching 1 when a single holder owns everything. Negative values are
+// treated as zero.
+func Gini(values []int) float64 {
+	sorted := make([]int, 0, len(values))
+	for _, v := range values {
+		sorted = append(sorted, max(v, 0))
+	}
+	slices.Sort(sorted)
+
+	n := len(sorted)
+	total := 0
+	weighted := 0
+	for i, v := range sorted {
+		total += v
+		weighted += (i + 1) * v
+	}
+	if n == 0 || total == 0 {
+		return 0
+	}
+	return (2*float64(weighted))/(float64(n)*float64(total)) - float64(n+1)/float64(n)
+}
+
+// TopShare returns the fraction of the total held by the richest share of
+// holders, always counting at least one holder.
+func TopShare(values []int, share float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+
+	top := int(math.Ceil(float64(len(sorted)) * share))
+	total, held := 0, 0
+	for i, v := range sorted {
+		total += v
+		if i < top {
+			held += v
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(held) / float64(total)
+}
+
+type Bucket struct {
+	Min   int
+	Max   int
+	Count int
+}
+
+// Histogram splits values into at most bins equal-width buckets covering
+// [min, max].
+func Histogram(values []int, bins int) []Bucket {
+	if len(values) == 0 || bins <= 0 {
+		return nil
+	}
+	lo, hi := slices.Min(values), slices.Max(values)
+	width := (hi - lo + bins) / bins
+	if width < 1 {
+		width = 1
+	}
+
+	var buckets []Bucket
+	for start := lo; start <= hi; start += width {
+		buckets = append(buckets, Bucket{Min: start, Max: start + width - 1})
+	}
+	for _, v := range values {
+		buckets[(v-lo)/width].Count++
+	}
+	return buckets
+}
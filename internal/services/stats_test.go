@@ -0,0 +1,120 @@
+package services
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   float64
+	}{
+		{name: "empty", values: nil, want: 0},
+		{name: "single value", values: []int{4}, want: 4},
+		{name: "all equal", values: []int{3, 3, 3, 3}, want: 3},
+		{name: "odd count unsorted", values: []int{3, 1, 2}, want: 2},
+		{name: "even count", values: []int{1, 4}, want: 2.5},
+		{name: "negative balances", values: []int{-4, 10, -2}, want: -2},
+		{name: "all negative", values: []int{-3, -1}, want: -2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Median(tt.values); got != tt.want {
+				t.Errorf("Median(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGini(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   float64
+	}{
+		{name: "empty", values: nil, want: 0},
+		{name: "single value", values: []int{5}, want: 0},
+		{name: "all equal", values: []int{3, 3, 3, 3}, want: 0},
+		{name: "all zero", values: []int{0, 0, 0}, want: 0},
+		{name: "one holder owns everything", values: []int{0, 10, 0, 0}, want: 0.75},
+		{name: "negative balances count as zero", values: []int{-5, 0, 10}, want: 2.0 / 3},
+		{name: "all negative", values: []int{-5, -1}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Gini(tt.values); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Gini(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		bins   int
+		want   []Bucket
+	}{
+		{name: "empty", values: nil, bins: 8, want: nil},
+		{name: "no bins", values: []int{1, 2}, bins: 0, want: nil},
+		{name: "single value", values: []int{5}, bins: 8, want: []Bucket{{Min: 5, Max: 5, Count: 1}}},
+		{name: "all equal", values: []int{3, 3, 3}, bins: 4, want: []Bucket{{Min: 3, Max: 3, Count: 3}}},
+		{
+			name:   "negative balances",
+			values: []int{-10, -1, 0, 9},
+			bins:   2,
+			want:   []Bucket{{Min: -10, Max: -1, Count: 2}, {Min: 0, Max: 9, Count: 2}},
+		},
+		{
+			name:   "fewer values than bins",
+			values: []int{0, 2},
+			bins:   8,
+			want:   []Bucket{{Min: 0, Max: 0, Count: 1}, {Min: 1, Max: 1}, {Min: 2, Max: 2, Count: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Histogram(tt.values, tt.bins)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Histogram(%v, %d) = %v, want %v", tt.values, tt.bins, got, tt.want)
+			}
+			total := 0
+			for _, b := range got {
+				total += b.Count
+			}
+			if total != len(tt.values) && tt.bins > 0 {
+				t.Errorf("buckets hold %d values, want %d", total, len(tt.values))
+			}
+		})
+	}
+}
+
+func TestTopShare(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		share  float64
+		want   float64
+	}{
+		{name: "empty", values: nil, share: 0.1, want: 0},
+		{name: "single value", values: []int{5}, share: 0.1, want: 1},
+		{name: "all equal", values: []int{1, 1, 1, 1}, share: 0.5, want: 0.5},
+		{name: "all zero", values: []int{0, 0}, share: 0.5, want: 0},
+		{name: "one holder owns everything", values: []int{0, 0, 10, 0}, share: 0.25, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TopShare(tt.values, tt.share); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("TopShare(%v, %v) = %v, want %v", tt.values, tt.share, got, tt.want)
+			}
+		})
+	}
+}